@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+func newCheckCommand() *cobra.Command {
+	o := &options{}
+	cmd := &cobra.Command{
+		Use:   "check <file> [package...]",
+		Short: "Check that a generated documentation file is up to date",
+		Long:  `This command generates the documentation in memory and compares it with an existing file. When they differ, it writes a diff to stderr and exits non-zero.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// an outdated file is not a usage error
+			cmd.SilenceUsage = true
+			return o.check(cmd.ErrOrStderr(), args[0], args[1:])
+		},
+	}
+	o.addFlags(cmd.Flags())
+	registerCompletions(cmd)
+	return cmd
+}
+
+// check compares the documentation generated for the packages matched by
+// args with the contents of file, writing a diff to errOut when they
+// differ. Stamps are ignored in the comparison.
+func (o *options) check(errOut io.Writer, file string, args []string) error {
+	current, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	var generated bytes.Buffer
+	if err := o.generate(&generated, errOut, args); err != nil {
+		return err
+	}
+	if diff := diffLines(file, "generated", stripStamp(string(current)), stripStamp(generated.String()), isTerminal(errOut)); diff != "" {
+		fmt.Fprintf(errOut, "%s is out of date:\n%s", file, diff)
+		return fmt.Errorf("%s is out of date", file)
+	}
+	return nil
+}
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// diffLines returns a unified diff from want to got, whose sides are
+// labelled wantName and gotName, or "" when they are equal. When colored
+// is set, removed lines are red and added lines green.
+func diffLines(wantName, gotName, want, got string, colored bool) string {
+	ops := diffOps(splitLines(want), splitLines(got))
+	if !slices.ContainsFunc(ops, func(op diffOp) bool { return op.Kind != ' ' }) {
+		return ""
+	}
+	removed := color.New(color.FgRed)
+	added := color.New(color.FgGreen)
+	if colored {
+		removed.EnableColor()
+		added.EnableColor()
+	} else {
+		removed.DisableColor()
+		added.DisableColor()
+	}
+
+	// lines[k] counts the lines of want and got before ops[k]
+	lines := make([][2]int, len(ops)+1)
+	for k, op := range ops {
+		lines[k+1] = lines[k]
+		if op.Kind != '+' {
+			lines[k+1][0]++
+		}
+		if op.Kind != '-' {
+			lines[k+1][1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", wantName, gotName)
+	for k := 0; k < len(ops); {
+		for k < len(ops) && ops[k].Kind == ' ' {
+			k++
+		}
+		if k == len(ops) {
+			break
+		}
+		// extend the hunk over changes separated by little enough context
+		// for theirs to overlap
+		end := k
+		for {
+			for end < len(ops) && ops[end].Kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(ops) && ops[next].Kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		start, stop := max(k-diffContext, 0), min(end+diffContext, len(ops))
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(lines[start][0], lines[stop][0]-lines[start][0]),
+			hunkRange(lines[start][1], lines[stop][1]-lines[start][1]))
+		for _, op := range ops[start:stop] {
+			line := string(op.Kind) + op.Text
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n\\ No newline at end of file\n"
+			}
+			switch op.Kind {
+			case '-':
+				b.WriteString(removed.Sprint(line))
+			case '+':
+				b.WriteString(added.Sprint(line))
+			default:
+				b.WriteString(line)
+			}
+		}
+		k = stop
+	}
+	return b.String()
+}
+
+// hunkRange formats the range of a hunk of count lines after the first
+// before lines, as in -3,4 of a unified diff header.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		// an empty range names the line before it
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines splits s into lines, each keeping its newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp is a line kept (' '), removed ('-') or added ('+') by a diff.
+type diffOp struct {
+	Kind byte
+	Text string
+}
+
+// maxDiffCells bounds the LCS table diffOps builds. Past it, the changed
+// middle of the two texts is shown as one removal and one addition, which
+// is still a correct, if less minimal, diff.
+const maxDiffCells = 1 << 22
+
+// diffOps returns the shortest edit turning a into b, computed from their
+// longest common subsequence of lines. Lines common to both ends are
+// trimmed first, so that only the changed middle needs the quadratic table.
+func diffOps(a, b []string) []diffOp {
+	var prefix, suffix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ops := make([]diffOp, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, lcsOps(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// lcsOps is diffOps without the trimming, falling back to replacing all of
+// a with all of b when the table would exceed maxDiffCells.
+func lcsOps(a, b []string) []diffOp {
+	var ops []diffOp
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// isTerminal reports whether w writes to a terminal, so that output to it
+// may be colored. NO_COLOR disables color regardless, as fatih/color does.
+func isTerminal(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheck(t *testing.T) {
+	o := &options{format: "markdown"}
+
+	var errOut bytes.Buffer
+	if err := o.check(&errOut, "testdata/check/config.md", []string{"testdata/check"}); err != nil {
+		t.Fatalf("check failed for an up-to-date file: %v\n%s", err, errOut.String())
+	}
+
+	current, err := os.ReadFile("testdata/check/config.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(t.TempDir(), "config.md")
+	content := strings.Replace(string(current), "Address to listen on", "Listen address", 1)
+	if err := os.WriteFile(stale, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	errOut.Reset()
+	if err := o.check(&errOut, stale, []string{"testdata/check"}); err == nil {
+		t.Fatal("check should fail for a stale file")
+	}
+	for _, want := range []string{"Listen address", "Address to listen on"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf("diff does not mention %q:\n%s", want, errOut.String())
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	if diff := diffLines("current", "generated", "a\nb\n", "a\nb\n", false); diff != "" {
+		t.Errorf("diffLines() of equal input = %q, want empty", diff)
+	}
+
+	want := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	got := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+	expected := `--- current
++++ generated
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+\ No newline at end of file
+`
+	if diff := cmp.Diff(expected, diffLines("current", "generated", want, got, false)); diff != "" {
+		t.Errorf("diffLines() mismatch (-want +got):\n%s", diff)
+	}
+
+	// changes close together share a hunk
+	expected = `--- current
++++ generated
+@@ -1,4 +1,3 @@
+-a
+ b
+ c
+-d
++D
+`
+	if diff := cmp.Diff(expected, diffLines("current", "generated", "a\nb\nc\nd\n", "b\nc\nD\n", false)); diff != "" {
+		t.Errorf("diffLines() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffLinesColor(t *testing.T) {
+	diff := diffLines("current", "generated", "a\n", "b\n", true)
+	if !strings.Contains(diff, "\x1b[31m-a\n") || !strings.Contains(diff, "\x1b[32m+b\n") {
+		t.Errorf("diffLines() with color = %q, want red and green lines", diff)
+	}
+}
+
+func TestDiffOpsLarge(t *testing.T) {
+	// the changed middle is too large for the LCS table, so it is replaced
+	// wholesale while the common ends are kept
+	var a, b []string
+	for i := range 3000 {
+		a = append(a, fmt.Sprint("a", i))
+		b = append(b, fmt.Sprint("b", i))
+	}
+	a = append(append([]string{"head"}, a...), "tail")
+	b = append(append([]string{"head"}, b...), "tail")
+
+	ops := diffOps(a, b)
+	var kept, removed, added int
+	for _, op := range ops {
+		switch op.Kind {
+		case ' ':
+			kept++
+		case '-':
+			removed++
+		case '+':
+			added++
+		}
+	}
+	if kept != 2 || removed != 3000 || added != 3000 {
+		t.Errorf("diffOps() kept %d, removed %d, added %d lines, want 2, 3000, 3000", kept, removed, added)
+	}
+	if ops[0] != (diffOp{' ', "head"}) || ops[len(ops)-1] != (diffOp{' ', "tail"}) {
+		t.Errorf("diffOps() = %v ... %v, want the common ends kept", ops[0], ops[len(ops)-1])
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	// errOut decides, regardless of where stdout goes
+	if isTerminal(&bytes.Buffer{}) {
+		t.Error("isTerminal() of a buffer = true")
+	}
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("isTerminal() of a regular file = true")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wreulicke/envconfig-docs/envconfigdocs"
+)
+
+// registerCompletions registers shell completions for the values of the
+// flags added by addFlags.
+func registerCompletions(cmd *cobra.Command) {
+	completions := map[string][]string{
+		"format":    slices.Sorted(maps.Keys(formats)),
+		"sort":      slices.Sorted(maps.Keys(envconfigdocs.KeyOrders)),
+		"tag-style": {"caarlos0", "kelsey"},
+		"tag":       {"env", "envconfig"},
+		"partition": {"required"},
+	}
+	for name, values := range completions {
+		// the flags exist, so registration cannot fail
+		_ = cmd.RegisterFlagCompletionFunc(name, completeValues(values))
+	}
+}
+
+// completeValues completes a flag with those of values that start with the
+// text typed so far.
+func completeValues(values []string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var matches []string
+		for _, value := range values {
+			if strings.HasPrefix(value, toComplete) {
+				matches = append(matches, value)
+			}
+		}
+		return matches, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,68 @@
+package envconfigdocs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteAsciiDoc writes each config type as a == heading, its comments and
+// an AsciiDoc table with the same columns as WriteMarkdown, for Antora and
+// other AsciiDoc toolchains.
+func WriteAsciiDoc(w io.Writer, configs map[string]*Config, opts *MarkdownOptions) error {
+	cols, err := opts.tableColumns()
+	if err != nil {
+		return err
+	}
+	writeLegend(w, opts)
+	withPackage := multiplePackages(configs)
+	for _, entry := range sortedConfigs(configs) {
+		config := entry.Value
+		if !opts.NoHeadings {
+			fmt.Fprintf(w, "== %s\n\n", asciidocText(sectionTitle(entry.Key, config)))
+			if withPackage && config.Package != "" {
+				fmt.Fprintf(w, "Package: `%s`\n\n", config.Package)
+			}
+			for _, c := range config.Comments {
+				fmt.Fprintf(w, "%s\n\n", asciidocText(strings.TrimSpace(c.Text())))
+			}
+			if config.Prefix != "" {
+				fmt.Fprintf(w, "Environment variables are prefixed with `%s`.\n\n", config.namePrefix())
+			}
+		}
+		for _, group := range partitionKeys(config.Keys, opts) {
+			if group.Title != "" {
+				fmt.Fprintf(w, "=== %s\n\n", group.Title)
+			}
+			fmt.Fprintln(w, `[options="header"]`)
+			fmt.Fprintln(w, "|===")
+			header := make([]string, len(cols))
+			for i, col := range cols {
+				header[i] = "|" + col.Header
+			}
+			fmt.Fprintln(w, strings.Join(header, " "))
+			for _, key := range group.Keys {
+				fmt.Fprintln(w)
+				for _, col := range cols {
+					fmt.Fprintf(w, "|%s\n", asciidocCell(col.Value(key)))
+				}
+			}
+			fmt.Fprint(w, "|===\n\n")
+		}
+	}
+	return nil
+}
+
+// asciidocText escapes braces in s, which AsciiDoc would otherwise read as
+// attribute references.
+func asciidocText(s string) string {
+	return strings.ReplaceAll(s, "{", `\{`)
+}
+
+// asciidocCell escapes s for use in an AsciiDoc table cell: besides braces,
+// pipes, which would end the cell, are backslash-escaped, and line breaks
+// become hard line breaks. Backticks are kept so that monospace text still
+// renders.
+func asciidocCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " +\n").Replace(asciidocText(s))
+}
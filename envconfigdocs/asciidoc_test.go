@@ -0,0 +1,82 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"go/ast"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteAsciiDoc(t *testing.T) {
+	configs := map[string]*Config{
+		"Config": {
+			Comments: []*ast.CommentGroup{{List: []*ast.Comment{{Text: "// Config is the app config."}}}},
+			Keys: []*Key{
+				{Name: "PORT", Type: "int", Required: true, Comment: "Port to listen on"},
+				{Name: "GREETING", Type: "string", Default: "{hi}", Comment: "Either hi | hello.\nSent on connect."},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAsciiDoc(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteAsciiDoc failed: %v", err)
+	}
+
+	expected := `== Config
+
+Config is the app config.
+
+[options="header"]
+|===
+|Name |Type |Required |Default |Comment
+
+|PORT
+|int
+|true
+|
+|Port to listen on
+
+|GREETING
+|string
+|false
+|"\{hi}"
+|Either hi \| hello. +
+Sent on connect.
+|===
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteAsciiDoc() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteAsciiDocPartitionRequired(t *testing.T) {
+	configs := map[string]*Config{
+		"Config": {
+			Keys: []*Key{{Name: "PORT", Type: "int", Required: true}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAsciiDoc(&buf, configs, &MarkdownOptions{Partition: PartitionRequired, Columns: []string{"name"}}); err != nil {
+		t.Fatalf("WriteAsciiDoc failed: %v", err)
+	}
+
+	expected := `== Config
+
+=== Required
+
+[options="header"]
+|===
+|Name
+
+|PORT
+|===
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteAsciiDoc() mismatch (-want +got):\n%s", diff)
+	}
+}
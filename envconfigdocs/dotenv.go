@@ -0,0 +1,43 @@
+package envconfigdocs
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteDotenv writes a .env template with one line per key: KEY=default when
+// the key has a default, or a commented-out KEY= otherwise, as for secrets.
+// Comments are written on the line above each key, and each type starts
+// with a banner.
+func WriteDotenv(w io.Writer, configs map[string]*Config) error {
+	for i, entry := range sortedConfigs(configs) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "# ---- %s ----\n", sectionTitle(entry.Key, entry.Value))
+		for _, key := range entry.Value.Keys {
+			if key.Comment != "" {
+				for _, line := range strings.Split(key.Comment, "\n") {
+					fmt.Fprintf(w, "# %s\n", line)
+				}
+			}
+			if key.Default == "" || key.Secret {
+				fmt.Fprintf(w, "# %s=\n", key.Name)
+			} else {
+				fmt.Fprintf(w, "%s=%s\n", key.Name, dotenvValue(key.Default))
+			}
+		}
+	}
+	return nil
+}
+
+// dotenvValue quotes value when it would otherwise be misread in a .env
+// file.
+func dotenvValue(value string) string {
+	if strings.ContainsAny(value, " \t\n#\"'\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
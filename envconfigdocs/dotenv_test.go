@@ -0,0 +1,43 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteDotenv(t *testing.T) {
+	configs := map[string]*Config{
+		"DBConfig": {
+			Keys: []*Key{
+				{Name: "DATABASE_URL", Type: "string", Default: "localhost:5432", Comment: "Database URL for connection"},
+				{Name: "API_KEY", Type: "string", Required: true},
+				{Name: "PASSWORD", Type: "string", Default: "hunter2", Secret: true},
+			},
+		},
+		"AppConfig": {
+			Keys: []*Key{
+				{Name: "GREETING", Type: "string", Default: "hello world"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDotenv(&buf, configs); err != nil {
+		t.Fatalf("WriteDotenv failed: %v", err)
+	}
+
+	expected := `# ---- AppConfig ----
+GREETING="hello world"
+
+# ---- DBConfig ----
+# Database URL for connection
+DATABASE_URL=localhost:5432
+# API_KEY=
+# PASSWORD=
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteDotenv() mismatch (-want +got):\n%s", diff)
+	}
+}
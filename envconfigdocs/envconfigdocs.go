@@ -0,0 +1,1101 @@
+// Package envconfigdocs generates documentation for Go configuration structs
+// read from the environment with envconfig-style struct tags.
+package envconfigdocs
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"iter"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gostaticanalysis/comment"
+	"golang.org/x/tools/go/packages"
+)
+
+// Config is a struct type read from the environment, with the keys it
+// reads in field declaration order.
+type Config struct {
+	Keys     []*Key
+	Comments []*ast.CommentGroup
+	// Prefix is the prefix passed to envconfig.Process or env.Options for
+	// this type, if any.
+	Prefix string
+	// Breadcrumb is the path of field names leading to this type from the
+	// root type named by CollectOptions.Root, starting with the root type's
+	// name.
+	Breadcrumb []string
+	// Package is the import path of the package declaring this type.
+	Package string
+	// parent is the outermost config type this type is nested in, if any.
+	// Keys are named as read through it and take its prefix.
+	parent *Config
+	// keyPrefix is the text ApplyPrefixes prepended to the key names.
+	keyPrefix string
+}
+
+// namePrefix returns the text the key names of c start with because of
+// c.Prefix, as envconfig.Process joins it unless ApplyPrefixes recorded
+// otherwise.
+func (c *Config) namePrefix() string {
+	if c.keyPrefix != "" {
+		return c.keyPrefix
+	}
+	return envName(c.Prefix, "")
+}
+
+// Key is an environment variable read by a config type.
+type Key struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+	// Underlying is the basic type underlying Type when Type is a named
+	// type or alias declared in the same package, e.g. int for Port.
+	Underlying string `json:"underlying,omitempty" yaml:"underlying,omitempty"`
+	Required   bool   `json:"required" yaml:"required"`
+	Default    string `json:"default,omitempty" yaml:"default,omitempty"`
+	// EmptyDefault reports whether the tag sets an explicitly empty default,
+	// e.g. default:"", which Default alone does not tell apart from none.
+	EmptyDefault bool   `json:"emptyDefault,omitempty" yaml:"emptyDefault,omitempty"`
+	Comment      string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	// Secret reports whether the field is tagged as holding a secret, see
+	// CollectOptions.SecretTag. Renderers redact its default, and JSON and
+	// YAML encodings leave it out.
+	Secret bool `json:"secret,omitempty" yaml:"secret,omitempty"`
+	// Values are the constants declared in the same package with Type as
+	// their type, in declaration order, when Type is an enum-like named type.
+	Values []string `json:"values,omitempty" yaml:"values,omitempty"`
+	// Pos is the file:line of the field declaring the key, with the file
+	// relative to the working directory when it is below it.
+	Pos string `json:"pos,omitempty" yaml:"pos,omitempty"`
+}
+
+// publicKey returns key, or a copy of it without the default when it is a
+// secret, for output that is not redacted otherwise.
+func publicKey(key *Key) *Key {
+	if !key.Secret || key.Default == "" {
+		return key
+	}
+	public := *key
+	public.Default = ""
+	return &public
+}
+
+type decl struct {
+	// Name is the name of the struct type.
+	Name string
+	// Decl is the declaration containing the struct, shared by every type
+	// of a grouped type ( ... ) declaration.
+	Decl *ast.GenDecl
+	// Spec is the spec declaring the struct within Decl, which holds
+	// several specs in a grouped type ( ... ) declaration.
+	Spec   *ast.TypeSpec
+	Fields []*ast.Field
+	// Pkg is the package declaring the struct, if known.
+	Pkg *packages.Package
+}
+
+type entry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+func entries[K comparable, V any](iter iter.Seq2[K, V]) func(yield func(*entry[K, V]) bool) {
+	return func(yield func(*entry[K, V]) bool) {
+		for k, v := range iter {
+			if !yield(&entry[K, V]{k, v}) {
+				break
+			}
+		}
+	}
+}
+
+func collectDecls(files []*ast.File) map[string]*decl {
+	decls := make(map[string]*decl)
+	for _, file := range files {
+		for _, d := range file.Decls {
+			genDecl, ok := d.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				if _, ok := typeSpec.Type.(*ast.StructType); ok {
+					decls[typeSpec.Name.Name] = &decl{
+						Name:   typeSpec.Name.Name,
+						Decl:   genDecl,
+						Spec:   typeSpec,
+						Fields: typeSpec.Type.(*ast.StructType).Fields.List,
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// packageDecls returns the struct declarations of pkg.
+func packageDecls(pkg *packages.Package) map[string]*decl {
+	decls := collectDecls(pkg.Syntax)
+	for _, d := range decls {
+		d.Pkg = pkg
+	}
+	return decls
+}
+
+func collectPackage(pkg *packages.Package, comments comment.Maps, opts *CollectOptions) map[string]*Config {
+	c := &collector{pkg: pkg, decls: packageDecls(pkg), style: opts.tagStyle(), descTags: opts.descTags(), secretTag: opts.secretTag(), defaultConsts: opts.DefaultConsts, warnf: opts.warnf}
+	configs := make(map[string]*Config)
+	// visit types in name order so that warnings are reported stably
+	for _, name := range slices.Sorted(maps.Keys(c.decls)) {
+		d := c.decls[name]
+		keys := c.collectKeys(d, map[*decl]bool{})
+		if len(keys) == 0 {
+			continue
+		}
+		configs[name] = &Config{
+			Keys:     keys,
+			Comments: typeComments(comments, d),
+		}
+	}
+	c.nestConfigs(configs)
+	if root, ok := c.decls[opts.Root]; ok {
+		c.setBreadcrumbs(configs, root, []string{opts.Root}, map[*decl]bool{})
+	}
+	return configs
+}
+
+// nestConfigs names the keys of the config types nested in other config
+// types the way they are read through the outermost one, e.g. DB_HOST
+// rather than HOST for a DBConfig nested under `envconfig:"DB"`, so that
+// every section lists the variables actually read. A type nested along
+// several paths takes the first one, visiting outermost types in name order
+// and fields in declaration order.
+func (c *collector) nestConfigs(configs map[string]*Config) {
+	names := map[*decl]string{}
+	nested := map[*decl]bool{}
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		d := c.decls[name]
+		names[d] = name
+		for child := range c.nestedStructs(d) {
+			if child != d {
+				nested[child] = true
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		if d := c.decls[name]; !nested[d] {
+			c.nestKeys(configs, names, configs[name], d, "", map[*decl]bool{d: true})
+		}
+	}
+}
+
+// nestKeys prefixes the keys of the config types nested in d, whose own
+// keys are read with prefix, and records parent as their parent.
+func (c *collector) nestKeys(configs map[string]*Config, names map[*decl]string, parent *Config, d *decl, prefix string, seen map[*decl]bool) {
+	for child, childPrefix := range c.nestedStructs(d) {
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		if name, ok := names[child]; ok && configs[name].parent == nil {
+			configs[name].parent = parent
+			prefixKeys(configs[name].Keys, prefix+childPrefix)
+		}
+		c.nestKeys(configs, names, parent, child, prefix+childPrefix, seen)
+	}
+}
+
+// nestedStructs yields the structs whose keys are read as part of those of
+// d, as collectKeys finds them, along with the prefix of their keys.
+func (c *collector) nestedStructs(d *decl) iter.Seq2[*decl, string] {
+	return func(yield func(*decl, string) bool) {
+		for _, field := range d.Fields {
+			prefix, prefixed := c.structPrefix(field)
+			if embedded, ok := c.embeddedDecl(d, field); ok {
+				if !yield(embedded, prefix) {
+					return
+				}
+				continue
+			}
+			if field.Tag == nil || field.Tag.Value == "" {
+				continue
+			}
+			nested, ok := c.typeDecl(d, field.Type)
+			if !ok {
+				continue
+			}
+			if prefixed {
+				if !yield(nested, prefix) {
+					return
+				}
+				continue
+			}
+			for _, name := range fieldNames(field) {
+				tag, ok := c.style.Parse(name, structTag(field))
+				if !ok || !token.IsExported(name) {
+					continue
+				}
+				if !yield(nested, tag.Name+"_") {
+					return
+				}
+			}
+		}
+	}
+}
+
+// typeComments returns the doc comments of the struct type d. In a grouped
+// type ( ... ) declaration they are those of its own spec, not the comment
+// above the group.
+func typeComments(comments comment.Maps, d *decl) []*ast.CommentGroup {
+	if !d.Decl.Lparen.IsValid() {
+		return comments.CommentsByPos(d.Decl.TokPos)
+	}
+	if d.Spec.Doc == nil {
+		return nil
+	}
+	return []*ast.CommentGroup{d.Spec.Doc}
+}
+
+// HasBreadcrumbs reports whether any of configs was reached from a root type.
+func HasBreadcrumbs(configs map[string]*Config) bool {
+	for _, config := range configs {
+		if config.Breadcrumb != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// setBreadcrumbs records path as the breadcrumb of the config types reached
+// from d through nested struct fields, walking fields in declaration order
+// so that a type reachable along several paths gets the first one. Fields
+// of embedded structs belong to the embedding struct's level.
+func (c *collector) setBreadcrumbs(configs map[string]*Config, d *decl, path []string, seen map[*decl]bool) {
+	if seen[d] {
+		return
+	}
+	seen[d] = true
+	for name, config := range configs {
+		if c.decls[name] == d && config.Breadcrumb == nil {
+			config.Breadcrumb = path
+		}
+	}
+	for _, field := range d.Fields {
+		nested, ok := c.typeDecl(d, field.Type)
+		if !ok {
+			continue
+		}
+		if len(field.Names) == 0 {
+			c.setBreadcrumbs(configs, nested, path, seen)
+			continue
+		}
+		for _, ident := range field.Names {
+			c.setBreadcrumbs(configs, nested, append(slices.Clip(path), ident.Name), seen)
+		}
+	}
+}
+
+// collector collects the keys of the struct declarations of a package.
+type collector struct {
+	pkg   *packages.Package
+	decls map[string]*decl
+	style *tagStyle
+	// descTags are the tag keys holding descriptions, in order of
+	// preference.
+	descTags []string
+	// secretTag is the tag key marking secrets.
+	secretTag string
+	// defaultConsts notes the constants matching each default.
+	defaultConsts bool
+	// warnf reports problems found while collecting.
+	warnf func(format string, args ...any)
+	// warned holds the fields already reported through warnOnce.
+	warned map[*ast.Field]bool
+	// imported caches the struct declarations of imported packages by
+	// import path.
+	imported map[string]map[string]*decl
+}
+
+// collectKeys returns the keys of d in field declaration order. The keys of
+// embedded structs are promoted in place of the embedded field, the way
+// envconfig processes them; tags on the embedding field itself are ignored,
+// so a promoted key is required only if its own field says so. The keys of
+// tagged struct fields are included with the field's name and an underscore
+// prepended, e.g. DB_HOST for a HOST key under `envconfig:"DB"`. Struct
+// fields carrying a prefix of the tag style, such as `envPrefix:"DB_"` of
+// caarlos0/env, whether embedded or not, get their keys prefixed with it
+// instead, so nested prefixes compose. visiting holds the structs currently
+// being collected so that recursive structs terminate.
+func (c *collector) collectKeys(d *decl, visiting map[*decl]bool) []*Key {
+	return c.structKeys(d, visiting, map[*decl]bool{})
+}
+
+// structKeys returns the keys of d like collectKeys. promoted holds the
+// embedded structs whose keys were already promoted, so that a struct
+// embedded through several paths contributes its keys only once, where it
+// is first embedded.
+func (c *collector) structKeys(d *decl, visiting, promoted map[*decl]bool) []*Key {
+	visiting[d] = true
+	defer delete(visiting, d)
+
+	var keys []*Key
+	for _, field := range d.Fields {
+		prefix, prefixed := c.structPrefix(field)
+		if embedded, ok := c.embeddedDecl(d, field); ok {
+			if prefixed && !visiting[embedded] {
+				// the prefix sets the keys apart from other embeddings
+				keys = append(keys, prefixKeys(c.collectKeys(embedded, visiting), prefix)...)
+			} else if !visiting[embedded] && !promoted[embedded] {
+				promoted[embedded] = true
+				keys = append(keys, c.structKeys(embedded, visiting, promoted)...)
+			}
+			continue
+		}
+		if field.Tag == nil || field.Tag.Value == "" {
+			continue
+		}
+		if nested, ok := c.typeDecl(d, field.Type); ok && prefixed {
+			if !visiting[nested] {
+				keys = append(keys, prefixKeys(c.collectKeys(nested, visiting), prefix)...)
+			}
+			continue
+		}
+		// fields declared together, as in Host, Port string, share one tag
+		names := fieldNames(field)
+		var first string
+		for i, name := range names {
+			tag, ok := c.style.Parse(name, structTag(field))
+			if !ok {
+				continue
+			}
+			if !token.IsExported(name) {
+				c.warnOnce(field, "%s.%s: unexported field is never set from the environment", d.Name, name)
+				continue
+			}
+			if i == 0 {
+				first = tag.Name
+			} else if tag.Name == first {
+				c.warnOnce(field, "%s: fields %s are declared together and all read %s", d.Name, strings.Join(names, ", "), tag.Name)
+				break
+			}
+			keys = append(keys, c.fieldKeys(d, field, tag, visiting)...)
+		}
+	}
+	return keys
+}
+
+// structPrefix returns the prefix the tag style prepends to the keys of the
+// struct referred to by field, if its tag sets one.
+func (c *collector) structPrefix(field *ast.Field) (string, bool) {
+	if c.style.StructPrefix == nil || field.Tag == nil || field.Tag.Value == "" {
+		return "", false
+	}
+	return c.style.StructPrefix(structTag(field))
+}
+
+// prefixKeys prepends prefix to the names of keys and returns them.
+func prefixKeys(keys []*Key, prefix string) []*Key {
+	for _, key := range keys {
+		key.Name = prefix + key.Name
+	}
+	return keys
+}
+
+// warnOnce reports a problem with field unless one was already reported,
+// since the fields of a struct are collected again for every struct that
+// nests it.
+func (c *collector) warnOnce(field *ast.Field, format string, args ...any) {
+	if c.warned[field] {
+		return
+	}
+	if c.warned == nil {
+		c.warned = map[*ast.Field]bool{}
+	}
+	c.warned[field] = true
+	c.warnf(format, args...)
+}
+
+// fieldKeys returns the keys read by field of d under the variable named by
+// tag: the keys of the struct it refers to, prefixed with tag.Name, or else a
+// single key.
+func (c *collector) fieldKeys(d *decl, field *ast.Field, tag fieldTag, visiting map[*decl]bool) []*Key {
+	if nested, ok := c.typeDecl(d, field.Type); ok && !visiting[nested] {
+		if nestedKeys := c.collectKeys(nested, visiting); len(nestedKeys) > 0 {
+			return prefixKeys(nestedKeys, tag.Name+"_")
+		}
+	}
+	// fall back to a trailing comment, as in Port int `envconfig:"PORT"` // listen port
+	comment := cmp.Or(commentText(field.Doc), commentText(field.Comment))
+	for _, key := range c.descTags {
+		if desc := structTag(field).Get(key); desc != "" {
+			comment = desc
+			break
+		}
+	}
+	if c.defaultConsts && tag.Default != "" {
+		if names := matchingConsts(d.Pkg, tag.Default); len(names) > 0 {
+			note := fmt.Sprintf("Default matches `%s`.", strings.Join(names, "`, `"))
+			if comment != "" {
+				note = comment + "\n" + note
+			}
+			comment = note
+		}
+	}
+	if tag.Required && tag.Default != "" {
+		c.warnOnce(field, "%s.%s: required has no effect because of the default %q", d.Name, tag.Name, tag.Default)
+	}
+	return []*Key{{
+		Name:         tag.Name,
+		Type:         typeString(field.Type),
+		Underlying:   underlyingType(d.Pkg, field.Type),
+		Required:     tag.Required,
+		Default:      tag.Default,
+		EmptyDefault: tag.EmptyDefault,
+		Comment:      comment,
+		Secret:       structTag(field).Get(c.secretTag) == "true",
+		Values:       enumValues(d.Pkg, field.Type),
+		Pos:          sourcePos(d.Pkg, field.Pos()),
+	}}
+}
+
+// sourcePos returns pos in pkg as file:line, or "" when pkg has no file set.
+func sourcePos(pkg *packages.Package, pos token.Pos) string {
+	if pkg == nil || pkg.Fset == nil || !pos.IsValid() {
+		return ""
+	}
+	position := pkg.Fset.Position(pos)
+	name := position.Filename
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, name); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+	}
+	return fmt.Sprintf("%s:%d", filepath.ToSlash(name), position.Line)
+}
+
+// fieldNames returns the names declared by field, or the name of the
+// embedded type for an embedded field.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		return []string{fieldName(field)}
+	}
+	names := make([]string, len(field.Names))
+	for i, ident := range field.Names {
+		names[i] = ident.Name
+	}
+	return names
+}
+
+// embeddedDecl returns the struct declaration embedded by field of owner,
+// if field is an embedded field of a struct type declared either in the
+// package of owner or, as a qualified identifier like shared.BaseConfig, in
+// a package it imports.
+func (c *collector) embeddedDecl(owner *decl, field *ast.Field) (*decl, bool) {
+	if len(field.Names) != 0 {
+		return nil, false
+	}
+	return c.typeDecl(owner, field.Type)
+}
+
+// typeDecl resolves the type expression expr used in owner to a struct
+// declaration. Pointers are followed, since envconfig allocates them.
+func (c *collector) typeDecl(owner *decl, expr ast.Expr) (*decl, bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return c.typeDecl(owner, t.X)
+	case *ast.Ident:
+		d, ok := c.declsOf(owner.Pkg)[t.Name]
+		return d, ok
+	case *ast.SelectorExpr:
+		return c.importedDecl(owner.Pkg, t)
+	case *ast.StructType:
+		// inline struct fields are read like those of a named struct and
+		// reported under the name of the struct declaring them
+		return &decl{Name: owner.Name, Fields: t.Fields.List, Pkg: owner.Pkg}, true
+	}
+	return nil, false
+}
+
+// declsOf returns the struct declarations of pkg, which is either the
+// package being collected or one of its dependencies.
+func (c *collector) declsOf(pkg *packages.Package) map[string]*decl {
+	if pkg == c.pkg {
+		return c.decls
+	}
+	if decls, ok := c.imported[pkg.PkgPath]; ok {
+		return decls
+	}
+	if c.imported == nil {
+		c.imported = map[string]map[string]*decl{}
+	}
+	decls := packageDecls(pkg)
+	c.imported[pkg.PkgPath] = decls
+	return decls
+}
+
+// importedDecl resolves the qualified identifier sel used in pkg to a struct
+// declaration in the imported package, using the type information of pkg.
+func (c *collector) importedDecl(pkg *packages.Package, sel *ast.SelectorExpr) (*decl, bool) {
+	if pkg == nil || pkg.TypesInfo == nil {
+		return nil, false
+	}
+	obj, ok := pkg.TypesInfo.Uses[sel.Sel].(*types.TypeName)
+	if !ok || obj.Pkg() == nil {
+		return nil, false
+	}
+	dep, ok := pkg.Imports[obj.Pkg().Path()]
+	if !ok {
+		return nil, false
+	}
+	d, ok := c.declsOf(dep)[obj.Name()]
+	return d, ok
+}
+
+// commentText returns the text of the comment group doc with the lines of
+// each paragraph joined by spaces, runs of whitespace collapsed, and
+// paragraphs separated by a newline. The leading asterisks of block comments
+// written in the
+//
+//	/*
+//	 * Port to listen on
+//	 */
+//
+// style are dropped.
+func commentText(doc *ast.CommentGroup) string {
+	text := doc.Text()
+	if doc != nil && strings.HasPrefix(doc.List[0].Text, "/*") {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(strings.TrimSpace(line), "*")
+		}
+		text = strings.Join(lines, "\n")
+	}
+	var paragraphs []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if text := strings.Join(strings.Fields(paragraph), " "); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return strings.Join(paragraphs, "\n")
+}
+
+// structTag returns the tag of field, unquoting both raw and interpreted
+// string literals such as "envconfig:\"HOST\"". A literal that does not
+// unquote yields an empty tag.
+func structTag(field *ast.Field) reflect.StructTag {
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag)
+}
+
+// typeString renders the type expression expr as written in the source,
+// abbreviating the methods of interfaces, the signatures of funcs and the
+// fields of inline structs. Other expressions, such as channels and
+// instantiated generic types, are rendered by types.ExprString.
+func typeString(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		return typeString(expr.X) + "." + expr.Sel.Name
+	case *ast.StarExpr:
+		return "*" + typeString(expr.X)
+	case *ast.ArrayType:
+		if expr.Len == nil {
+			return "[]" + typeString(expr.Elt)
+		}
+		return "[" + types.ExprString(expr.Len) + "]" + typeString(expr.Elt)
+	case *ast.MapType:
+		return "map[" + typeString(expr.Key) + "]" + typeString(expr.Value)
+	case *ast.InterfaceType:
+		if len(expr.Methods.List) == 0 {
+			return "interface{}"
+		}
+		return "interface{...}"
+	case *ast.FuncType:
+		return "func(...)"
+	case *ast.StructType:
+		if len(expr.Fields.List) == 0 {
+			return "struct{}"
+		}
+		return "struct{...}"
+	default:
+		return types.ExprString(expr)
+	}
+}
+
+// underlyingType returns the name of the basic type underlying expr when
+// expr names a type declared in pkg, such as Port in type Port int, using
+// the type information of pkg. It returns "" for any other type.
+func underlyingType(pkg *packages.Package, expr ast.Expr) string {
+	if pkg == nil || pkg.TypesInfo == nil {
+		return ""
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	var obj *types.TypeName
+	switch t := pkg.TypesInfo.TypeOf(ident).(type) {
+	case *types.Named:
+		obj = t.Obj()
+	case *types.Alias:
+		obj = t.Obj()
+	default:
+		return ""
+	}
+	if obj.Pkg() == nil || obj.Pkg() != pkg.Types {
+		return ""
+	}
+	basic, ok := obj.Type().Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	return basic.Name()
+}
+
+// enumValues returns the values of the constants declared in pkg whose type
+// is the named type expr, such as Debug and Info in
+//
+//	type LogLevel string
+//
+//	const (
+//		Debug LogLevel = "debug"
+//		Info  LogLevel = "info"
+//	)
+//
+// in declaration order. String values are returned unquoted. It returns nil
+// when expr is not a named type declared in pkg.
+func enumValues(pkg *packages.Package, expr ast.Expr) []string {
+	if pkg == nil || pkg.TypesInfo == nil {
+		return nil
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	named, ok := pkg.TypesInfo.TypeOf(ident).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg() != pkg.Types {
+		return nil
+	}
+	var values []string
+	for _, file := range pkg.Syntax {
+		for _, d := range file.Decls {
+			genDecl, ok := d.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					obj, ok := pkg.TypesInfo.Defs[name].(*types.Const)
+					if !ok || name.Name == "_" || !types.Identical(obj.Type(), named) {
+						continue
+					}
+					values = append(values, constValue(obj))
+				}
+			}
+		}
+	}
+	return values
+}
+
+// constValue returns the value of obj as it would be written in a tag:
+// strings unquoted, other constants in their exact form.
+func constValue(obj *types.Const) string {
+	if obj.Val().Kind() == constant.String {
+		return constant.StringVal(obj.Val())
+	}
+	return obj.Val().ExactString()
+}
+
+// matchingConsts returns the names of the package-level constants of pkg
+// whose value is value as written in a tag, e.g. defaultTimeout for
+// default:"30" when const defaultTimeout = 30, in declaration order.
+func matchingConsts(pkg *packages.Package, value string) []string {
+	if pkg == nil || pkg.TypesInfo == nil {
+		return nil
+	}
+	var names []string
+	for _, file := range pkg.Syntax {
+		for _, d := range file.Decls {
+			genDecl, ok := d.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					obj, ok := pkg.TypesInfo.Defs[name].(*types.Const)
+					if ok && name.Name != "_" && constValue(obj) == value {
+						names = append(names, name.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// LoadPackages loads the packages matched by patterns, such as ./... or an
+// import path resolvable through the module cache. Paths of existing
+// directories are treated as relative package paths even without a leading
+// ./, so that testdata/config means ./testdata/config. Errors reported for
+// the matched packages are returned joined. Those of their dependencies are
+// not, since they do not affect the docs unless they break the matched
+// packages too, which then report errors of their own.
+func LoadPackages(patterns ...string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedImports | packages.NeedDeps,
+	}
+	patterns = slices.Clone(patterns)
+	for i, pattern := range patterns {
+		if filepath.IsAbs(pattern) || strings.HasPrefix(pattern, ".") {
+			continue
+		}
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			patterns[i] = "./" + filepath.ToSlash(pattern)
+		}
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	// packages that fail to parse or type-check would yield partial docs
+	var errs []error
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			errs = append(errs, err)
+		}
+	}
+	return pkgs, errors.Join(errs...)
+}
+
+// CollectOptions controls how config types are collected from packages.
+type CollectOptions struct {
+	// DescMapVar names a package-level map[string]string variable holding
+	// descriptions keyed by environment variable name. Descriptions found
+	// there replace the doc comments of the matching keys.
+	DescMapVar string
+	// DescTag names a struct tag key, such as "help", holding the
+	// description of a field. Fields without it keep their doc comment.
+	// The default is the description key of the tag style, if any.
+	DescTag string
+	// DescTags names further description tag keys, tried in order after
+	// DescTag, e.g. desc then help. The first non-empty one is used.
+	DescTags []string
+	// SecretTag names a struct tag key marking fields that hold secrets
+	// with "true", e.g. secret:"true". The default is DefaultSecretTag.
+	SecretTag string
+	// TagStyle names the tag conventions to read: kelsey for
+	// kelseyhightower/envconfig or caarlos0 for caarlos0/env. The default
+	// is DefaultTagStyle.
+	TagStyle string
+	// Root names the top-level config type. Types nested below it get a
+	// breadcrumb showing where they sit in the hierarchy.
+	Root string
+	// DefaultConsts notes in the comment of each key with a default the
+	// package-level constants of the same value, e.g. "Default matches
+	// `defaultTimeout`." for default:"30" and const defaultTimeout = 30,
+	// so that tags mirroring a constant can be kept in sync with it.
+	DefaultConsts bool
+	// Warnf reports problems that do not stop the collection. It may be
+	// nil.
+	Warnf func(format string, args ...any)
+}
+
+// warnf reports a problem through o.Warnf, if set.
+func (o *CollectOptions) warnf(format string, args ...any) {
+	if o.Warnf != nil {
+		o.Warnf(format, args...)
+	}
+}
+
+// DefaultSecretTag is the tag key marking secrets when none is selected.
+const DefaultSecretTag = "secret"
+
+// secretTag returns the tag key marking secrets selected by o.
+func (o *CollectOptions) secretTag() string {
+	return cmp.Or(o.SecretTag, DefaultSecretTag)
+}
+
+// descTags returns the tag keys holding descriptions selected by o, in
+// order of preference.
+func (o *CollectOptions) descTags() []string {
+	var keys []string
+	if o.DescTag != "" {
+		keys = append(keys, o.DescTag)
+	}
+	keys = append(keys, o.DescTags...)
+	if len(keys) == 0 {
+		if style := o.tagStyle(); style != nil && style.DescKey != "" {
+			keys = append(keys, style.DescKey)
+		}
+	}
+	return keys
+}
+
+// Validate reports options that name unknown tag conventions.
+func (o *CollectOptions) Validate() error {
+	if o.tagStyle() == nil {
+		return fmt.Errorf("unsupported tag style %q", o.TagStyle)
+	}
+	return nil
+}
+
+// tagStyle returns the tag conventions selected by o.
+func (o *CollectOptions) tagStyle() *tagStyle {
+	if o.TagStyle == "" {
+		return tagStyles[DefaultTagStyle]
+	}
+	return tagStyles[o.TagStyle]
+}
+
+// CollectConfigTypes returns the struct types of pkgs that read environment
+// variables, keyed by type name. Names shared by types of several packages
+// are qualified with the package path.
+func CollectConfigTypes(pkgs []*packages.Package, opts *CollectOptions) map[string]*Config {
+	configs := map[string]*Config{}
+	// owners records the package of each unqualified name in configs
+	owners := map[string]*packages.Package{}
+
+	for _, pkg := range pkgs {
+		// type comments need positions; without them keys are still collected
+		var comments comment.Maps
+		if pkg.Fset != nil {
+			comments = comment.New(pkg.Fset, pkg.Syntax)
+		}
+
+		configInPkg := collectPackage(pkg, comments, opts)
+		if opts.DescMapVar != "" {
+			applyDescriptions(configInPkg, descriptionMap(pkg, opts.DescMapVar))
+		}
+		for _, name := range slices.Sorted(maps.Keys(configInPkg)) {
+			config := configInPkg[name]
+			config.Package = packagePath(pkg)
+			// types of the same name in different packages are qualified
+			// with their package path, e.g. example.com/app.Config
+			if owner, ok := owners[name]; ok {
+				if owner != nil {
+					configs[packagePath(owner)+"."+name] = configs[name]
+					delete(configs, name)
+					owners[name] = nil
+				}
+				configs[packagePath(pkg)+"."+name] = config
+				continue
+			}
+			configs[name] = config
+			owners[name] = pkg
+		}
+	}
+
+	return configs
+}
+
+// packagePath returns the import path of pkg, falling back to its name when
+// the path is unknown.
+func packagePath(pkg *packages.Package) string {
+	switch {
+	case pkg.PkgPath != "":
+		return pkg.PkgPath
+	case pkg.Name != "":
+		return pkg.Name
+	case len(pkg.Syntax) > 0:
+		return pkg.Syntax[0].Name.Name
+	}
+	return ""
+}
+
+// descriptionMap reads the package-level map literal named name in pkg,
+// mapping environment variable names to descriptions. Keys and values are
+// evaluated with the package's type information when available, so named
+// string constants work as well as literals; other entries are skipped.
+func descriptionMap(pkg *packages.Package, name string) map[string]string {
+	descriptions := map[string]string{}
+	for _, file := range pkg.Syntax {
+		for _, d := range file.Decls {
+			genDecl, ok := d.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for i, ident := range valueSpec.Names {
+					if ident.Name != name || i >= len(valueSpec.Values) {
+						continue
+					}
+					lit, ok := valueSpec.Values[i].(*ast.CompositeLit)
+					if !ok {
+						continue
+					}
+					for _, elt := range lit.Elts {
+						kv, ok := elt.(*ast.KeyValueExpr)
+						if !ok {
+							continue
+						}
+						key, ok := constantString(pkg, kv.Key)
+						if !ok {
+							continue
+						}
+						value, ok := constantString(pkg, kv.Value)
+						if !ok {
+							continue
+						}
+						descriptions[key] = value
+					}
+				}
+			}
+		}
+	}
+	return descriptions
+}
+
+// constantString evaluates expr as a constant string.
+func constantString(pkg *packages.Package, expr ast.Expr) (string, bool) {
+	if pkg.TypesInfo != nil {
+		if tv, ok := pkg.TypesInfo.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			return constant.StringVal(tv.Value), true
+		}
+	}
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// applyDescriptions replaces the comments of keys found in descriptions.
+func applyDescriptions(configs map[string]*Config, descriptions map[string]string) {
+	for _, config := range configs {
+		for _, key := range config.Keys {
+			if desc, ok := descriptions[key.Name]; ok {
+				key.Comment = desc
+			}
+		}
+	}
+}
+
+// ApplyPrefixes prefixes the keys of each config type listed in prefixes the
+// way the library selected by opts does: as PREFIX_NAME in upper case for
+// envconfig.Process, or verbatim for caarlos0/env's Options.Prefix. Types
+// nested in another config type take the prefix of the outermost one, since
+// that is the type passed to the library.
+func ApplyPrefixes(configs map[string]*Config, prefixes map[string]string, opts *CollectOptions) error {
+	names := map[*Config]string{}
+	for name, config := range configs {
+		names[config] = name
+	}
+	for _, name := range slices.Sorted(maps.Keys(prefixes)) {
+		if _, ok := configs[name]; !ok {
+			return fmt.Errorf("unknown config type %q", name)
+		}
+	}
+	join := opts.tagStyle().JoinPrefix
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		config := configs[name]
+		if config.parent != nil {
+			name = names[config.parent]
+		}
+		prefix := prefixes[name]
+		if prefix == "" {
+			continue
+		}
+		config.Prefix = prefix
+		config.keyPrefix = join(prefix, "")
+		for _, key := range config.Keys {
+			key.Name = join(prefix, key.Name)
+		}
+	}
+	return nil
+}
+
+// SelectTypes removes the config types not listed in names, failing when
+// one of names is not a config type. An empty names keeps every type.
+func SelectTypes(configs map[string]*Config, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		if _, ok := configs[name]; !ok {
+			return fmt.Errorf("type %q not found, available types: %s", name, strings.Join(slices.Sorted(maps.Keys(configs)), ", "))
+		}
+	}
+	maps.DeleteFunc(configs, func(name string, _ *Config) bool {
+		return !slices.Contains(names, name)
+	})
+	return nil
+}
+
+// ExcludeTypes removes the config types whose names match any of patterns,
+// using path.Match syntax.
+func ExcludeTypes(configs map[string]*Config, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	maps.DeleteFunc(configs, func(name string, _ *Config) bool {
+		return slices.ContainsFunc(patterns, func(pattern string) bool {
+			matched, _ := path.Match(pattern, name)
+			return matched
+		})
+	})
+	return nil
+}
+
+// KeyOrders maps the names of key orders to comparisons of keys for
+// SortKeys. Keys comparing equal keep their declaration order.
+var KeyOrders = map[string]func(a, b *Key) int{
+	"declaration": func(a, b *Key) int { return 0 },
+	"name":        func(a, b *Key) int { return strings.Compare(a.Name, b.Name) },
+	"required": func(a, b *Key) int {
+		switch {
+		case a.Required == b.Required:
+			return 0
+		case a.Required:
+			return -1
+		default:
+			return 1
+		}
+	},
+}
+
+// SortKeys sorts the keys of each config type with compare, keeping the
+// declaration order of keys that compare equal.
+func SortKeys(configs map[string]*Config, compare func(a, b *Key) int) {
+	for _, config := range configs {
+		slices.SortStableFunc(config.Keys, compare)
+	}
+}
+
+// GroupByPackage groups configs by the import path of the package declaring
+// them. Names qualified with their package path because they are declared
+// in several packages are unqualified within their group.
+func GroupByPackage(configs map[string]*Config) map[string]map[string]*Config {
+	groups := map[string]map[string]*Config{}
+	for name, config := range configs {
+		group, ok := groups[config.Package]
+		if !ok {
+			group = map[string]*Config{}
+			groups[config.Package] = group
+		}
+		group[strings.TrimPrefix(name, config.Package+".")] = config
+	}
+	return groups
+}
@@ -0,0 +1,1632 @@
+package envconfigdocs
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/tools/go/packages"
+)
+
+// parseTestPackage parses src as the only file, test.go, of a package.
+func parseTestPackage(t *testing.T, src string) *packages.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	return &packages.Package{
+		Fset:   fset,
+		Syntax: []*ast.File{file},
+	}
+}
+
+// checkTestPackage parses src like parseTestPackage and type-checks it.
+func checkTestPackage(t *testing.T, src string) *packages.Package {
+	t.Helper()
+	pkg := parseTestPackage(t, src)
+	pkg.TypesInfo = &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	var err error
+	pkg.Types, err = (&types.Config{Importer: importer.Default()}).Check("test", pkg.Fset, pkg.Syntax, pkg.TypesInfo)
+	if err != nil {
+		t.Fatalf("failed to type-check source: %v", err)
+	}
+	return pkg
+}
+
+// ignorePos ignores the source positions of keys, which most tests do not
+// care about, along with the nesting recorded for ApplyPrefixes.
+var ignorePos = cmp.Options{cmpopts.IgnoreFields(Key{}, "Pos"), cmpopts.IgnoreUnexported(Config{})}
+
+func TestCollectConfigTypesFromPackages(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected map[string]*Config
+	}{
+		{
+			name: "single config with envconfig tags",
+			source: `
+package test
+
+// MyConfig is a test configuration
+type MyConfig struct {
+	// Database URL for connection
+	DatabaseURL string ` + "`envconfig:\"DATABASE_URL\" required:\"true\" default:\"localhost:5432\"`" + `
+	// API Key for authentication
+	APIKey string ` + "`envconfig:\"API_KEY\" required:\"false\"`" + `
+	// Max connections allowed
+	MaxConnections int ` + "`envconfig:\"MAX_CONN\" default:\"10\"`" + `
+}
+`,
+			expected: map[string]*Config{
+				"MyConfig": {
+					Package: "test",
+					Keys: []*Key{
+						{
+							Name:     "DATABASE_URL",
+							Type:     "string",
+							Required: true,
+							Default:  "localhost:5432",
+							Comment:  "Database URL for connection",
+						},
+						{
+							Name:     "API_KEY",
+							Type:     "string",
+							Required: false,
+							Default:  "",
+							Comment:  "API Key for authentication",
+						},
+						{
+							Name:     "MAX_CONN",
+							Type:     "int",
+							Required: false,
+							Default:  "10",
+							Comment:  "Max connections allowed",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "multiple configs in same package",
+			source: `
+package test
+
+type Config1 struct {
+	Field1 string ` + "`envconfig:\"FIELD1\"`" + `
+}
+
+type Config2 struct {
+	Field2 int ` + "`envconfig:\"FIELD2\" required:\"true\"`" + `
+}
+`,
+			expected: map[string]*Config{
+				"Config1": {
+					Package: "test",
+					Keys: []*Key{
+						{Name: "FIELD1", Type: "string", Required: false},
+					},
+				},
+				"Config2": {
+					Package: "test",
+					Keys: []*Key{
+						{Name: "FIELD2", Type: "int", Required: true},
+					},
+				},
+			},
+		},
+		{
+			name: "generic field types",
+			source: `
+package test
+
+type Opt[T any] struct{ V T }
+
+type Pair[K comparable, V any] struct{}
+
+type GenericConfig struct {
+	Retries Opt[int]            ` + "`envconfig:\"RETRIES\"`" + `
+	Limits  Pair[string, int]   ` + "`envconfig:\"LIMITS\"`" + `
+	Events  <-chan Opt[string] ` + "`envconfig:\"EVENTS\"`" + `
+}
+`,
+			expected: map[string]*Config{
+				"GenericConfig": {
+					Package: "test",
+					Keys: []*Key{
+						{Name: "RETRIES", Type: "Opt[int]"},
+						{Name: "LIMITS", Type: "Pair[string, int]"},
+						{Name: "EVENTS", Type: "<-chan Opt[string]"},
+					},
+				},
+			},
+		},
+		{
+			name: "pointer field types",
+			source: `
+package test
+
+type PointerConfig struct {
+	Timeout *int ` + "`envconfig:\"TIMEOUT\"`" + `
+	Name    **string ` + "`envconfig:\"NAME\"`" + `
+	Done    chan int ` + "`envconfig:\"DONE\"`" + `
+}
+`,
+			expected: map[string]*Config{
+				"PointerConfig": {
+					Package: "test",
+					Keys: []*Key{
+						{Name: "TIMEOUT", Type: "*int"},
+						{Name: "NAME", Type: "**string"},
+						{Name: "DONE", Type: "chan int"},
+					},
+				},
+			},
+		},
+		{
+			name: "interface and func field types",
+			source: `
+package test
+
+type HookConfig struct {
+	Any      any                         ` + "`envconfig:\"ANY\"`" + `
+	Empty    interface{}                 ` + "`envconfig:\"EMPTY\"`" + `
+	Stringer interface{ String() string } ` + "`envconfig:\"STRINGER\"`" + `
+	Hook     func(int) error             ` + "`envconfig:\"HOOK\"`" + `
+}
+`,
+			expected: map[string]*Config{
+				"HookConfig": {
+					Package: "test",
+					Keys: []*Key{
+						{Name: "ANY", Type: "any"},
+						{Name: "EMPTY", Type: "interface{}"},
+						{Name: "STRINGER", Type: "interface{...}"},
+						{Name: "HOOK", Type: "func(...)"},
+					},
+				},
+			},
+		},
+		{
+			name: "map and slice field types",
+			source: `
+package test
+
+type MapConfig struct {
+	Labels  map[string]string ` + "`envconfig:\"LABELS\"`" + `
+	Ports   map[string][]int ` + "`envconfig:\"PORTS\"`" + `
+	Hosts   []string ` + "`envconfig:\"HOSTS\"`" + `
+	Weights [3]float64 ` + "`envconfig:\"WEIGHTS\"`" + `
+}
+`,
+			expected: map[string]*Config{
+				"MapConfig": {
+					Package: "test",
+					Keys: []*Key{
+						{Name: "LABELS", Type: "map[string]string"},
+						{Name: "PORTS", Type: "map[string][]int"},
+						{Name: "HOSTS", Type: "[]string"},
+						{Name: "WEIGHTS", Type: "[3]float64"},
+					},
+				},
+			},
+		},
+		{
+			name: "qualified field types",
+			source: `
+package test
+
+import (
+	"net/url"
+	"time"
+)
+
+type QualifiedConfig struct {
+	Timeout  time.Duration ` + "`envconfig:\"TIMEOUT\" default:\"30s\"`" + `
+	Endpoint *url.URL ` + "`envconfig:\"ENDPOINT\"`" + `
+}
+`,
+			expected: map[string]*Config{
+				"QualifiedConfig": {
+					Package: "test",
+					Keys: []*Key{
+						{Name: "TIMEOUT", Type: "time.Duration", Default: "30s"},
+						{Name: "ENDPOINT", Type: "*url.URL"},
+					},
+				},
+			},
+		},
+		{
+			name: "struct without envconfig tags",
+			source: `
+package test
+
+type NoEnvConfig struct {
+	Field1 string
+	Field2 int ` + "`json:\"field2\"`" + `
+}
+`,
+			expected: map[string]*Config{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Parse the source code
+			pkg := parseTestPackage(t, tt.source)
+
+			// Test the function
+			result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+			// Compare results (ignoring Comments field for simplicity)
+			for _, config := range result {
+				config.Comments = nil
+			}
+
+			if diff := cmp.Diff(tt.expected, result, ignorePos); diff != "" {
+				t.Errorf("CollectConfigTypes() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCollectConfigTypesFromPackagesMultiplePackages(t *testing.T) {
+	// Test with multiple packages
+	source1 := `
+package pkg1
+
+type Config1 struct {
+	Field1 string ` + "`envconfig:\"FIELD1\"`" + `
+}
+`
+	source2 := `
+package pkg2
+
+type Config2 struct {
+	Field2 string ` + "`envconfig:\"FIELD2\"`" + `
+}
+`
+
+	pkg1 := parseTestPackage(t, source1)
+	pkg2 := parseTestPackage(t, source2)
+
+	result := CollectConfigTypes([]*packages.Package{pkg1, pkg2}, &CollectOptions{})
+
+	expected := map[string]*Config{
+		"Config1": {
+			Package: "pkg1",
+			Keys: []*Key{
+				{Name: "FIELD1", Type: "string", Required: false},
+			},
+		},
+		"Config2": {
+			Package: "pkg2",
+			Keys: []*Key{
+				{Name: "FIELD2", Type: "string", Required: false},
+			},
+		},
+	}
+
+	// Ignore Comments field for comparison
+	for _, config := range result {
+		config.Comments = nil
+	}
+
+	if diff := cmp.Diff(expected, result, ignorePos); diff != "" {
+		t.Errorf("CollectConfigTypes() with multiple packages mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesFromPackagesDuplicateNames(t *testing.T) {
+	source := `
+package %s
+
+type Config struct {
+	Field string ` + "`envconfig:\"%s\"`" + `
+}
+`
+	var pkgs []*packages.Package
+	for _, name := range []string{"pkg1", "pkg2", "pkg3"} {
+		pkg := parseTestPackage(t, fmt.Sprintf(source, name, strings.ToUpper(name)))
+		pkg.PkgPath = "example.com/" + name
+		pkgs = append(pkgs, pkg)
+	}
+
+	result := CollectConfigTypes(pkgs, &CollectOptions{})
+	for _, config := range result {
+		config.Comments = nil
+	}
+
+	expected := map[string]*Config{
+		"example.com/pkg1.Config": {Keys: []*Key{{Name: "PKG1", Type: "string"}}, Package: "example.com/pkg1"},
+		"example.com/pkg2.Config": {Keys: []*Key{{Name: "PKG2", Type: "string"}}, Package: "example.com/pkg2"},
+		"example.com/pkg3.Config": {Keys: []*Key{{Name: "PKG3", Type: "string"}}, Package: "example.com/pkg3"},
+	}
+	if diff := cmp.Diff(expected, result, ignorePos); diff != "" {
+		t.Errorf("CollectConfigTypes() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCommentText(t *testing.T) {
+	source := `
+package test
+
+type Config struct {
+	// First sentence.
+	// Second   sentence.
+	//
+	// Second paragraph.
+	Field string
+}
+`
+	file := parseTestPackage(t, source).Syntax[0]
+	field := file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType).Fields.List[0]
+
+	expected := "First sentence. Second sentence.\nSecond paragraph."
+	if got := commentText(field.Doc); got != expected {
+		t.Errorf("commentText() = %q, want %q", got, expected)
+	}
+	if got := commentText(nil); got != "" {
+		t.Errorf("commentText(nil) = %q, want empty", got)
+	}
+}
+
+func TestCollectConfigTypesPos(t *testing.T) {
+	pkgs, err := LoadPackages("testdata/crosspkg/app")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+
+	result := CollectConfigTypes(pkgs, &CollectOptions{})
+
+	var got []string
+	for _, key := range result["AppConfig"].Keys {
+		got = append(got, key.Name+" "+key.Pos)
+	}
+	// promoted keys point at the embedded struct's own fields
+	expected := []string{
+		"LOG_LEVEL testdata/crosspkg/shared/shared.go:6",
+		"LOG_FORMAT testdata/crosspkg/shared/shared.go:13",
+		"PORT testdata/crosspkg/app/app.go:11",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("positions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesTrailingComments(t *testing.T) {
+	source := `
+package test
+
+type MyConfig struct {
+	Port int ` + "`envconfig:\"PORT\"`" + ` // listen port
+	// Host to bind
+	Host string ` + "`envconfig:\"HOST\"`" + ` // ignored in favor of the doc comment
+	Name string ` + "`envconfig:\"NAME\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	expected := []*Key{
+		{Name: "PORT", Type: "int", Comment: "listen port"},
+		{Name: "HOST", Type: "string", Comment: "Host to bind"},
+		{Name: "NAME", Type: "string"},
+	}
+	if diff := cmp.Diff(expected, result["MyConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("MyConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesGroupedComments(t *testing.T) {
+	source := `
+package test
+
+// Config types of the service.
+type (
+	// AppConfig configures the application.
+	AppConfig struct {
+		Port int ` + "`envconfig:\"PORT\"`" + `
+	}
+
+	DBConfig struct {
+		Host string ` + "`envconfig:\"DB_HOST\"`" + `
+	}
+)
+
+// LogConfig configures logging.
+type LogConfig struct {
+	Level string ` + "`envconfig:\"LOG_LEVEL\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	expected := map[string][]string{
+		"AppConfig": {"AppConfig configures the application.\n"},
+		"DBConfig":  nil,
+		"LogConfig": {"LogConfig configures logging.\n"},
+	}
+	for name, want := range expected {
+		var got []string
+		for _, group := range result[name].Comments {
+			got = append(got, group.Text())
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("%s comments mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestCollectConfigTypesGroupedDecls(t *testing.T) {
+	source := `
+package test
+
+type (
+	AppConfig struct {
+		Port int ` + "`envconfig:\"PORT\"`" + `
+		DBConfig
+	}
+
+	DBConfig struct {
+		Host string ` + "`envconfig:\"DB_HOST\"`" + `
+	}
+
+	LogConfig struct {
+		Level string ` + "`envconfig:\"LOG_LEVEL\"`" + `
+	}
+)
+`
+	pkg := parseTestPackage(t, source)
+
+	decls := collectDecls(pkg.Syntax)
+	for _, name := range []string{"AppConfig", "DBConfig", "LogConfig"} {
+		if got := decls[name].Spec.Name.Name; got != name {
+			t.Errorf("decl %s has the spec of %s", name, got)
+		}
+	}
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+	got := map[string][]*Key{}
+	for name, config := range result {
+		got[name] = config.Keys
+	}
+	expected := map[string][]*Key{
+		"AppConfig": {
+			{Name: "PORT", Type: "int", Pos: "test.go:6"},
+			{Name: "DB_HOST", Type: "string", Pos: "test.go:11"},
+		},
+		"DBConfig":  {{Name: "DB_HOST", Type: "string", Pos: "test.go:11"}},
+		"LogConfig": {{Name: "LOG_LEVEL", Type: "string", Pos: "test.go:15"}},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCommentTextBlockComments(t *testing.T) {
+	source := `
+package test
+
+type Config struct {
+	/* Port to
+	   listen on */
+	Port int
+	/*
+	 * Host to bind.
+	 *
+	 * Defaults to all interfaces.
+	 */
+	Host string
+}
+`
+	file := parseTestPackage(t, source).Syntax[0]
+	fields := file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType).Fields.List
+
+	expected := []string{"Port to listen on", "Host to bind.\nDefaults to all interfaces."}
+	for i, field := range fields {
+		if got := commentText(field.Doc); got != expected[i] {
+			t.Errorf("commentText() = %q, want %q", got, expected[i])
+		}
+	}
+}
+
+func TestApplyPrefixes(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "PORT", Type: "int"}},
+		},
+		"DBConfig": {
+			Keys: []*Key{{Name: "host", Type: "string"}},
+		},
+		"Other": {
+			Keys: []*Key{{Name: "NAME", Type: "string"}},
+		},
+	}
+
+	if err := ApplyPrefixes(configs, map[string]string{"AppConfig": "myapp", "DBConfig": "DB"}, &CollectOptions{}); err != nil {
+		t.Fatalf("ApplyPrefixes failed: %v", err)
+	}
+
+	expected := map[string]*Config{
+		"AppConfig": {
+			Keys:   []*Key{{Name: "MYAPP_PORT", Type: "int"}},
+			Prefix: "myapp",
+		},
+		"DBConfig": {
+			Keys:   []*Key{{Name: "DB_HOST", Type: "string"}},
+			Prefix: "DB",
+		},
+		"Other": {
+			Keys: []*Key{{Name: "NAME", Type: "string"}},
+		},
+	}
+	if diff := cmp.Diff(expected, configs, ignorePos); diff != "" {
+		t.Errorf("ApplyPrefixes() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := ApplyPrefixes(configs, map[string]string{"Missing": "X"}, &CollectOptions{}); err == nil {
+		t.Error("ApplyPrefixes() with an unknown type should fail")
+	}
+}
+
+func TestApplyPrefixesCaarlos0(t *testing.T) {
+	// caarlos0/env prepends Options.Prefix as given, without an underscore
+	// or a change of case
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "PORT", Type: "int"}},
+		},
+	}
+
+	if err := ApplyPrefixes(configs, map[string]string{"AppConfig": "MyApp_"}, &CollectOptions{TagStyle: "caarlos0"}); err != nil {
+		t.Fatalf("ApplyPrefixes failed: %v", err)
+	}
+	if diff := cmp.Diff([]*Key{{Name: "MyApp_PORT", Type: "int"}}, configs["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("ApplyPrefixes() mismatch (-want +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Environment variables are prefixed with `MyApp_`.") {
+		t.Errorf("WriteMarkdown() does not show the verbatim prefix:\n%s", buf.String())
+	}
+}
+
+func TestCollectConfigTypesInlineStructs(t *testing.T) {
+	source := `
+package test
+
+type AppConfig struct {
+	DB struct {
+		// Database host
+		Host string ` + "`envconfig:\"HOST\" default:\"localhost\"`" + `
+		Pool *struct {
+			Size int ` + "`envconfig:\"SIZE\"`" + `
+		} ` + "`envconfig:\"POOL\"`" + `
+	} ` + "`envconfig:\"DB\"`" + `
+	Extra struct {
+		Untagged string
+	} ` + "`envconfig:\"EXTRA\"`" + `
+	Ignored struct {
+		Value string ` + "`envconfig:\"VALUE\"`" + `
+	}
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	expected := []*Key{
+		{Name: "DB_HOST", Type: "string", Default: "localhost", Comment: "Database host"},
+		{Name: "DB_POOL_SIZE", Type: "int"},
+		{Name: "EXTRA", Type: "struct{...}"},
+	}
+	if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("AppConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesEffectiveNames(t *testing.T) {
+	source := `
+package test
+
+type AppConfig struct {
+	Port           int ` + "`envconfig:\"port\"`" + `
+	MaxConnections int ` + "`split_words:\"true\"`" + `
+	DB             DBConfig ` + "`envconfig:\"db\"`" + `
+}
+
+type DBConfig struct {
+	Host string ` + "`envconfig:\"host\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	configs := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+	if err := ApplyPrefixes(configs, map[string]string{"AppConfig": "myapp"}, &CollectOptions{}); err != nil {
+		t.Fatalf("ApplyPrefixes failed: %v", err)
+	}
+
+	var names []string
+	for _, key := range configs["AppConfig"].Keys {
+		names = append(names, key.Name)
+	}
+	expected := []string{"MYAPP_PORT", "MYAPP_MAX_CONNECTIONS", "MYAPP_DB_HOST"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("AppConfig names mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSelectTypes(t *testing.T) {
+	configs := map[string]*Config{"AppConfig": {}, "DBConfig": {}, "TestConfig": {}}
+
+	if err := SelectTypes(configs, []string{"AppConfig", "DBConfig"}); err != nil {
+		t.Fatalf("SelectTypes failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"AppConfig", "DBConfig"}, slices.Sorted(maps.Keys(configs))); diff != "" {
+		t.Errorf("selected types mismatch (-want +got):\n%s", diff)
+	}
+
+	err := SelectTypes(configs, []string{"AppConfg"})
+	if err == nil || !strings.Contains(err.Error(), "available types: AppConfig, DBConfig") {
+		t.Errorf("SelectTypes error = %v, want one listing the available types", err)
+	}
+}
+
+func TestGroupByPackage(t *testing.T) {
+	appConfig := &Config{Package: "example.com/app"}
+	dbConfig := &Config{Package: "example.com/app/db"}
+	dbOptions := &Config{Package: "example.com/app/db"}
+	configs := map[string]*Config{
+		"example.com/app.Config":    appConfig,
+		"example.com/app/db.Config": dbConfig,
+		"Options":                   dbOptions,
+	}
+
+	expected := map[string]map[string]*Config{
+		"example.com/app":    {"Config": appConfig},
+		"example.com/app/db": {"Config": dbConfig, "Options": dbOptions},
+	}
+	if diff := cmp.Diff(expected, GroupByPackage(configs), ignorePos); diff != "" {
+		t.Errorf("GroupByPackage() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExcludeTypes(t *testing.T) {
+	configs := map[string]*Config{"AppConfig": {}, "AppTest": {}, "internalConfig": {}, "DBConfig": {}}
+
+	if err := ExcludeTypes(configs, []string{"*Test", "internal*"}); err != nil {
+		t.Fatalf("ExcludeTypes failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"AppConfig", "DBConfig"}, slices.Sorted(maps.Keys(configs))); diff != "" {
+		t.Errorf("remaining types mismatch (-want +got):\n%s", diff)
+	}
+
+	err := ExcludeTypes(configs, []string{"[App"})
+	if err == nil || err.Error() != `invalid pattern "[App": syntax error in pattern` {
+		t.Errorf("ExcludeTypes() with a malformed pattern error = %v", err)
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	keys := func() []*Key {
+		return []*Key{
+			{Name: "PORT", Type: "int"},
+			{Name: "HOST", Type: "string", Required: true},
+			{Name: "DEBUG", Type: "bool"},
+			{Name: "API_KEY", Type: "string", Required: true},
+		}
+	}
+	tests := map[string][]string{
+		"declaration": {"PORT", "HOST", "DEBUG", "API_KEY"},
+		"name":        {"API_KEY", "DEBUG", "HOST", "PORT"},
+		// ties keep declaration order
+		"required": {"HOST", "API_KEY", "PORT", "DEBUG"},
+	}
+	for order, expected := range tests {
+		configs := map[string]*Config{"Config": {Keys: keys()}}
+		SortKeys(configs, KeyOrders[order])
+		var got []string
+		for _, key := range configs["Config"].Keys {
+			got = append(got, key.Name)
+		}
+		if diff := cmp.Diff(expected, got, ignorePos); diff != "" {
+			t.Errorf("SortKeys(%s) mismatch (-want +got):\n%s", order, diff)
+		}
+	}
+}
+
+func TestLoadPackagesFromModuleCache(t *testing.T) {
+	proxy := t.TempDir()
+	writeModuleProxy(t, proxy, "example.com/lib", "v1.0.0", "testdata/modcache/lib")
+
+	app := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.23\n\nrequire example.com/lib v1.0.0\n"
+	if err := os.WriteFile(filepath.Join(app, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "-mod=mod -modcacherw")
+	t.Setenv("GOMODCACHE", t.TempDir())
+	chdir(t, app)
+
+	pkgs, err := LoadPackages("example.com/lib/config")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+	result := CollectConfigTypes(pkgs, &CollectOptions{})
+	for _, config := range result {
+		config.Comments = nil
+	}
+
+	expected := map[string]*Config{
+		"LibConfig": {
+			Package: "example.com/lib/config",
+			Keys: []*Key{
+				{Name: "LIB_ENDPOINT", Type: "string", Required: true, Comment: "Endpoint of the remote service"},
+				{Name: "LIB_RETRIES", Type: "int", Default: "3", Comment: "Retries before giving up"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, result, ignorePos); diff != "" {
+		t.Errorf("CollectConfigTypes() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// writeModuleProxy lays out the module in dir as version of module path in
+// a GOPROXY file tree rooted at proxy.
+func writeModuleProxy(t *testing.T, proxy, path, version, dir string) {
+	t.Helper()
+
+	root := filepath.Join(proxy, filepath.FromSlash(path), "@v")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"list":            version + "\n",
+		version + ".info": `{"Version":"` + version + `","Time":"2025-01-01T00:00:00Z"}`,
+		version + ".mod":  string(goMod),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(path + "@" + version + "/" + filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, version+".zip"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// chdir changes the working directory to dir for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestCollectConfigTypesEmbeddedOrder(t *testing.T) {
+	source := `
+package test
+
+type DBConfig struct {
+	Host string ` + "`envconfig:\"DB_HOST\"`" + `
+	Port int    ` + "`envconfig:\"DB_PORT\"`" + `
+}
+
+type CacheConfig struct {
+	URL string ` + "`envconfig:\"CACHE_URL\"`" + `
+	TTL int    ` + "`envconfig:\"CACHE_TTL\"`" + `
+}
+
+type AppConfig struct {
+	Name string ` + "`envconfig:\"APP_NAME\"`" + `
+	CacheConfig
+	DBConfig
+	Debug bool ` + "`envconfig:\"DEBUG\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	expected := []*Key{
+		{Name: "APP_NAME", Type: "string"},
+		{Name: "CACHE_URL", Type: "string"},
+		{Name: "CACHE_TTL", Type: "int"},
+		{Name: "DB_HOST", Type: "string"},
+		{Name: "DB_PORT", Type: "int"},
+		{Name: "DEBUG", Type: "bool"},
+	}
+	// map iteration order varies between runs, so collect repeatedly
+	for range 20 {
+		result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+		if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+			t.Fatalf("AppConfig keys mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestCollectConfigTypesRequiredWithDefault(t *testing.T) {
+	source := `
+package test
+
+type DBConfig struct {
+	Port int ` + "`envconfig:\"DB_PORT\" required:\"true\" default:\"5432\"`" + `
+}
+
+type AppConfig struct {
+	DBConfig
+	Host string ` + "`envconfig:\"HOST\" required:\"true\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	var warnings []string
+	CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{
+		Warnf: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	})
+
+	// reported once although DBConfig is collected twice
+	expected := []string{`DBConfig.DB_PORT: required has no effect because of the default "5432"`}
+	if diff := cmp.Diff(expected, warnings); diff != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesInterpretedTags(t *testing.T) {
+	source := `
+package test
+
+type AppConfig struct {
+	Host     string "envconfig:\"HOST\" default:\"localhost\""
+	Greeting string "envconfig:\"GREETING\" default:\"hello\\tworld\""
+	Port     int    ` + "`envconfig:\"PORT\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+	expected := []*Key{
+		{Name: "HOST", Type: "string", Default: "localhost"},
+		{Name: "GREETING", Type: "string", Default: "hello\tworld"},
+		{Name: "PORT", Type: "int"},
+	}
+	if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("AppConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesWithoutFset(t *testing.T) {
+	source := `
+package test
+
+// AppConfig is the application configuration.
+type AppConfig struct {
+	// Host to listen on
+	Host string ` + "`envconfig:\"HOST\"`" + `
+}
+`
+	file := parseTestPackage(t, source).Syntax[0]
+
+	tests := map[string]*packages.Package{
+		"nil Fset":   {Syntax: []*ast.File{file}},
+		"nil Syntax": {Fset: token.NewFileSet()},
+	}
+	for name, pkg := range tests {
+		result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+		if pkg.Syntax == nil {
+			if len(result) != 0 {
+				t.Errorf("%s: expected no configs, got %v", name, result)
+			}
+			continue
+		}
+		expected := []*Key{{Name: "HOST", Type: "string", Comment: "Host to listen on"}}
+		if diff := cmp.Diff(expected, result["AppConfig"].Keys); diff != "" {
+			t.Errorf("%s: AppConfig keys mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestCollectConfigTypesUnexported(t *testing.T) {
+	source := `
+package test
+
+type AppConfig struct {
+	Host   string ` + "`envconfig:\"HOST\"`" + `
+	secret string ` + "`envconfig:\"SECRET\"`" + `
+	cache  string
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	var warnings []string
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{
+		Warnf: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	})
+
+	expected := []*Key{{Name: "HOST", Type: "string"}}
+	if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("AppConfig keys mismatch (-want +got):\n%s", diff)
+	}
+	expectedWarnings := []string{"AppConfig.secret: unexported field is never set from the environment"}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesEmbeddedTwice(t *testing.T) {
+	source := `
+package test
+
+type Common struct {
+	LogLevel string ` + "`envconfig:\"LOG_LEVEL\"`" + `
+}
+
+type Server struct {
+	Common
+	Port int ` + "`envconfig:\"PORT\"`" + `
+}
+
+type Worker struct {
+	Common
+	Queue string ` + "`envconfig:\"QUEUE\"`" + `
+}
+
+type Replica struct {
+	Common
+}
+
+type AppConfig struct {
+	Name string ` + "`envconfig:\"NAME\"`" + `
+	Server
+	Worker
+	Primary Replica ` + "`envconfig:\"PRIMARY\"`" + `
+	Standby Replica ` + "`envconfig:\"STANDBY\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	// Common is promoted once, where Server embeds it, while the tagged
+	// fields keep their own copies under their prefixes
+	expected := []*Key{
+		{Name: "NAME", Type: "string"},
+		{Name: "LOG_LEVEL", Type: "string"},
+		{Name: "PORT", Type: "int"},
+		{Name: "QUEUE", Type: "string"},
+		{Name: "PRIMARY_LOG_LEVEL", Type: "string"},
+		{Name: "STANDBY_LOG_LEVEL", Type: "string"},
+	}
+	// map iteration order varies between runs, so collect repeatedly
+	for range 20 {
+		result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+		if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+			t.Fatalf("AppConfig keys mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestCollectConfigTypesMultipleNames(t *testing.T) {
+	source := `
+package test
+
+type Inner struct {
+	// Shared by both
+	A, B string ` + "`envconfig:\"SHARED\"`" + `
+}
+
+type AppConfig struct {
+	// Hosts to connect to
+	Host, Port string ` + "`split_words:\"true\"`" + `
+	Inner      Inner  ` + "`envconfig:\"INNER\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	var warnings []string
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{
+		Warnf: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	})
+
+	// derived names give one key per field; an explicit name is read once
+	expected := []*Key{
+		{Name: "HOST", Type: "string", Comment: "Hosts to connect to"},
+		{Name: "PORT", Type: "string", Comment: "Hosts to connect to"},
+		{Name: "INNER_SHARED", Type: "string", Comment: "Shared by both"},
+	}
+	if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("AppConfig keys mismatch (-want +got):\n%s", diff)
+	}
+	expectedWarnings := []string{"Inner: fields A, B are declared together and all read SHARED"}
+	if diff := cmp.Diff(expectedWarnings, warnings); diff != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesEmbeddedPointer(t *testing.T) {
+	source := `
+package test
+
+type DBConfig struct {
+	Host string ` + "`envconfig:\"DB_HOST\"`" + `
+}
+
+type AppConfig struct {
+	*DBConfig
+	Debug bool ` + "`envconfig:\"DEBUG\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	expected := []*Key{
+		{Name: "DB_HOST", Type: "string"},
+		{Name: "DEBUG", Type: "bool"},
+	}
+	if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("AppConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesNestedPrefix(t *testing.T) {
+	source := `
+package test
+
+type PoolConfig struct {
+	Size int ` + "`envconfig:\"SIZE\" default:\"4\"`" + `
+}
+
+type DBConfig struct {
+	Host string     ` + "`envconfig:\"HOST\" required:\"true\"`" + `
+	Pool PoolConfig ` + "`envconfig:\"POOL\"`" + `
+}
+
+type Node struct {
+	Name string ` + "`envconfig:\"NAME\"`" + `
+	Next *Node  ` + "`envconfig:\"NEXT\"`" + `
+}
+
+type AppConfig struct {
+	DB    DBConfig ` + "`envconfig:\"DB\"`" + `
+	Debug bool     ` + "`envconfig:\"DEBUG\"`" + `
+	Root  Node     ` + "`envconfig:\"ROOT\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	expected := []*Key{
+		{Name: "DB_HOST", Type: "string", Required: true},
+		{Name: "DB_POOL_SIZE", Type: "int", Default: "4"},
+		{Name: "DEBUG", Type: "bool"},
+		// the cycle back to Node is documented as a plain key
+		{Name: "ROOT_NAME", Type: "string"},
+		{Name: "ROOT_NEXT", Type: "*Node"},
+	}
+	if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("AppConfig keys mismatch (-want +got):\n%s", diff)
+	}
+
+	// nested types are named as read through AppConfig
+	nested := map[string][]string{
+		"DBConfig":   {"DB_HOST", "DB_POOL_SIZE"},
+		"PoolConfig": {"DB_POOL_SIZE"},
+		"Node":       {"ROOT_NAME", "ROOT_NEXT"},
+	}
+	for name, want := range nested {
+		var got []string
+		for _, key := range result[name].Keys {
+			got = append(got, key.Name)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("%s keys mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+
+	if err := ApplyPrefixes(result, map[string]string{"AppConfig": "myapp"}, &CollectOptions{}); err != nil {
+		t.Fatalf("ApplyPrefixes failed: %v", err)
+	}
+	if got := result["PoolConfig"].Keys[0].Name; got != "MYAPP_DB_POOL_SIZE" {
+		t.Errorf("PoolConfig key = %q, want the prefix of AppConfig", got)
+	}
+}
+
+func TestCollectConfigTypesEmbeddedRequired(t *testing.T) {
+	source := `
+package test
+
+type DBConfig struct {
+	Host string ` + "`envconfig:\"DB_HOST\" required:\"true\"`" + `
+	Port int    ` + "`envconfig:\"DB_PORT\" default:\"5432\"`" + `
+}
+
+type AppConfig struct {
+	DBConfig
+}
+
+type StrictConfig struct {
+	DBConfig ` + "`required:\"true\"`" + `
+}
+
+type LaxConfig struct {
+	DBConfig ` + "`required:\"false\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	// the inner field tags decide Required, regardless of the embedding field
+	expected := []*Key{
+		{Name: "DB_HOST", Type: "string", Required: true},
+		{Name: "DB_PORT", Type: "int", Default: "5432"},
+	}
+	for _, name := range []string{"AppConfig", "StrictConfig", "LaxConfig"} {
+		if diff := cmp.Diff(expected, result[name].Keys, ignorePos); diff != "" {
+			t.Errorf("%s keys mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestCollectConfigTypesDescTag(t *testing.T) {
+	source := `
+package test
+
+type Config struct {
+	// Port to listen on
+	Port int ` + "`envconfig:\"PORT\" help:\"TCP port of the HTTP server\"`" + `
+	// Host to bind
+	Host string ` + "`envconfig:\"HOST\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{DescTag: "help"})
+
+	expected := []*Key{
+		{Name: "PORT", Type: "int", Comment: "TCP port of the HTTP server"},
+		{Name: "HOST", Type: "string", Comment: "Host to bind"},
+	}
+	if diff := cmp.Diff(expected, result["Config"].Keys, ignorePos); diff != "" {
+		t.Errorf("Config keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesDescTags(t *testing.T) {
+	source := `
+package test
+
+type Config struct {
+	// Port to listen on
+	Port int ` + "`envconfig:\"PORT\" help:\"TCP port\" doc:\"Port of the HTTP server\"`" + `
+	// Host to bind
+	Host string ` + "`envconfig:\"HOST\" doc:\"\" help:\"Interface to bind\"`" + `
+	// Name of the service
+	Name string ` + "`envconfig:\"NAME\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{DescTags: []string{"doc", "help"}})
+
+	expected := []*Key{
+		{Name: "PORT", Type: "int", Comment: "Port of the HTTP server"},
+		{Name: "HOST", Type: "string", Comment: "Interface to bind"},
+		{Name: "NAME", Type: "string", Comment: "Name of the service"},
+	}
+	if diff := cmp.Diff(expected, result["Config"].Keys, ignorePos); diff != "" {
+		t.Errorf("Config keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesDescTagDefault(t *testing.T) {
+	source := `
+package test
+
+type Config struct {
+	// Port to listen on
+	Port int ` + "`envconfig:\"PORT\" desc:\"TCP port of the HTTP server\"`" + `
+	// Host to bind
+	Host string ` + "`envconfig:\"HOST\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	// desc wins over the doc comment
+	expected := []*Key{
+		{Name: "PORT", Type: "int", Comment: "TCP port of the HTTP server"},
+		{Name: "HOST", Type: "string", Comment: "Host to bind"},
+	}
+	if diff := cmp.Diff(expected, result["Config"].Keys, ignorePos); diff != "" {
+		t.Errorf("Config keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesUnderlying(t *testing.T) {
+	source := `
+package test
+
+import "time"
+
+type Port int
+
+type Level = string
+
+type Hosts []string
+
+type MyConfig struct {
+	Listen  Port          ` + "`envconfig:\"PORT\"`" + `
+	Level   Level         ` + "`envconfig:\"LEVEL\"`" + `
+	Hosts   Hosts         ` + "`envconfig:\"HOSTS\"`" + `
+	Timeout time.Duration ` + "`envconfig:\"TIMEOUT\"`" + `
+	Name    string        ` + "`envconfig:\"NAME\"`" + `
+}
+`
+	pkg := checkTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	// only named basic types declared in the package are resolved
+	expected := []*Key{
+		{Name: "PORT", Type: "Port", Underlying: "int"},
+		{Name: "LEVEL", Type: "Level", Underlying: "string"},
+		{Name: "HOSTS", Type: "Hosts"},
+		{Name: "TIMEOUT", Type: "time.Duration"},
+		{Name: "NAME", Type: "string"},
+	}
+	if diff := cmp.Diff(expected, result["MyConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("MyConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesEnumValues(t *testing.T) {
+	source := `
+package test
+
+type LogLevel string
+
+const (
+	Debug LogLevel = "debug"
+	Info  LogLevel = "info"
+	Warn  LogLevel = "warn"
+)
+
+const Fallback = "info"
+
+type Mode int
+
+const (
+	ModeA Mode = iota
+	ModeB
+)
+
+type Alias = string
+
+const Other Alias = "other"
+
+type MyConfig struct {
+	Level LogLevel ` + "`envconfig:\"LEVEL\"`" + `
+	Mode  Mode     ` + "`envconfig:\"MODE\"`" + `
+	Alias Alias    ` + "`envconfig:\"ALIAS\"`" + `
+	Name  string   ` + "`envconfig:\"NAME\"`" + `
+}
+`
+	pkg := checkTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	// aliases are not enums, since any string constant would match
+	expected := []*Key{
+		{Name: "LEVEL", Type: "LogLevel", Underlying: "string", Values: []string{"debug", "info", "warn"}},
+		{Name: "MODE", Type: "Mode", Underlying: "int", Values: []string{"0", "1"}},
+		{Name: "ALIAS", Type: "Alias", Underlying: "string"},
+		{Name: "NAME", Type: "string"},
+	}
+	if diff := cmp.Diff(expected, result["MyConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("MyConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesDefaultConsts(t *testing.T) {
+	source := `
+package test
+
+const (
+	defaultTimeout = 30
+	defaultRetries = 30
+	defaultHost    = "localhost"
+)
+
+type MyConfig struct {
+	// Request timeout in seconds
+	Timeout int    ` + "`envconfig:\"TIMEOUT\" default:\"30\"`" + `
+	Host    string ` + "`envconfig:\"HOST\" default:\"localhost\"`" + `
+	Port    int    ` + "`envconfig:\"PORT\" default:\"8080\"`" + `
+}
+`
+	pkg := checkTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{DefaultConsts: true})
+
+	expected := []*Key{
+		{Name: "TIMEOUT", Type: "int", Default: "30", Comment: "Request timeout in seconds\nDefault matches `defaultTimeout`, `defaultRetries`."},
+		{Name: "HOST", Type: "string", Default: "localhost", Comment: "Default matches `defaultHost`."},
+		{Name: "PORT", Type: "int", Default: "8080"},
+	}
+	if diff := cmp.Diff(expected, result["MyConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("MyConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesSecretTag(t *testing.T) {
+	source := `
+package test
+
+type MyConfig struct {
+	Password string ` + "`envconfig:\"PASSWORD\" default:\"hunter2\" secret:\"true\"`" + `
+	Token    string ` + "`envconfig:\"TOKEN\" sensitive:\"true\"`" + `
+	Host     string ` + "`envconfig:\"HOST\" secret:\"false\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	tests := []struct {
+		secretTag string
+		expected  []*Key
+	}{
+		{
+			expected: []*Key{
+				{Name: "PASSWORD", Type: "string", Default: "hunter2", Secret: true},
+				{Name: "TOKEN", Type: "string"},
+				{Name: "HOST", Type: "string"},
+			},
+		},
+		{
+			secretTag: "sensitive",
+			expected: []*Key{
+				{Name: "PASSWORD", Type: "string", Default: "hunter2"},
+				{Name: "TOKEN", Type: "string", Secret: true},
+				{Name: "HOST", Type: "string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{SecretTag: tt.secretTag})
+		if diff := cmp.Diff(tt.expected, result["MyConfig"].Keys, ignorePos); diff != "" {
+			t.Errorf("SecretTag %q: MyConfig keys mismatch (-want +got):\n%s", tt.secretTag, diff)
+		}
+	}
+}
+
+func TestCollectConfigTypesFromPackagesDescMapVar(t *testing.T) {
+	source := `
+package test
+
+const apiKey = "API_KEY"
+
+var descriptions = map[string]string{
+	"DATABASE_URL": "Database URL for connection",
+	apiKey:         "API key for " + "authentication",
+}
+
+type MyConfig struct {
+	// overridden by the description map
+	DatabaseURL string ` + "`envconfig:\"DATABASE_URL\"`" + `
+	APIKey      string ` + "`envconfig:\"API_KEY\"`" + `
+	// kept as there is no description
+	Port int ` + "`envconfig:\"PORT\"`" + `
+}
+`
+	pkg := checkTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{DescMapVar: "descriptions"})
+
+	expected := []*Key{
+		{Name: "DATABASE_URL", Type: "string", Comment: "Database URL for connection"},
+		{Name: "API_KEY", Type: "string", Comment: "API key for authentication"},
+		{Name: "PORT", Type: "int", Comment: "kept as there is no description"},
+	}
+	if diff := cmp.Diff(expected, result["MyConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("MyConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesCrossPackageEmbedded(t *testing.T) {
+	pkgs, err := LoadPackages("testdata/crosspkg/app")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+
+	result := CollectConfigTypes(pkgs, &CollectOptions{})
+
+	expected := []*Key{
+		{Name: "LOG_LEVEL", Type: "string", Default: "info", Comment: "Log level of the service"},
+		{Name: "LOG_FORMAT", Type: "string", Default: "json", Comment: "Log output format"},
+		{Name: "PORT", Type: "int", Required: true, Comment: "Port to listen on"},
+	}
+	if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("AppConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesCrossPackageNested(t *testing.T) {
+	pkgs, err := LoadPackages("testdata/crosspkg/app")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+
+	result := CollectConfigTypes(pkgs, &CollectOptions{})
+
+	expected := []*Key{
+		{Name: "SERVICE_LOG_FORMAT", Type: "string", Default: "json", Comment: "Log output format"},
+		{Name: "FALLBACK_LOG_LEVEL", Type: "string", Default: "info", Comment: "Log level of the service"},
+		{Name: "FALLBACK_LOG_FORMAT", Type: "string", Default: "json", Comment: "Log output format"},
+	}
+	if diff := cmp.Diff(expected, result["ServiceConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("ServiceConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadPackagesRecursivePattern(t *testing.T) {
+	pkgs, err := LoadPackages("./testdata/crosspkg/...")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+
+	result := CollectConfigTypes(pkgs, &CollectOptions{})
+
+	for _, name := range []string{"AppConfig", "BaseConfig", "LoggingConfig"} {
+		if _, ok := result[name]; !ok {
+			t.Errorf("%s not collected from ./testdata/crosspkg/...", name)
+		}
+	}
+}
+
+func TestLoadPackagesErrors(t *testing.T) {
+	_, err := LoadPackages("testdata/broken")
+	if err == nil || !strings.Contains(err.Error(), "undefined: Address") {
+		t.Errorf("LoadPackages error = %v, want the type error of testdata/broken", err)
+	}
+}
+
+func TestLoadPackagesDependencyErrors(t *testing.T) {
+	pkgs, err := LoadPackages("testdata/brokendep/app")
+	if err != nil {
+		t.Fatalf("LoadPackages failed on an error of a dependency only: %v", err)
+	}
+
+	result := CollectConfigTypes(pkgs, &CollectOptions{})
+	expected := []*Key{{Name: "HOST", Type: "string"}}
+	if diff := cmp.Diff(expected, result["Config"].Keys, ignorePos); diff != "" {
+		t.Errorf("Config keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollectConfigTypesRootBreadcrumbs(t *testing.T) {
+	source := `
+package test
+
+type App struct {
+	Name     string ` + "`envconfig:\"NAME\"`" + `
+	Database DatabaseConfig
+	Common
+}
+
+type Common struct {
+	Cache CacheConfig
+}
+
+type DatabaseConfig struct {
+	URL  string ` + "`envconfig:\"DB_URL\"`" + `
+	Pool PoolConfig
+}
+
+type PoolConfig struct {
+	Size int ` + "`envconfig:\"POOL_SIZE\"`" + `
+}
+
+type CacheConfig struct {
+	TTL int ` + "`envconfig:\"CACHE_TTL\"`" + `
+}
+
+type Unrelated struct {
+	Value string ` + "`envconfig:\"VALUE\"`" + `
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{Root: "App"})
+
+	got := map[string][]string{}
+	for name, config := range result {
+		got[name] = config.Breadcrumb
+	}
+	expected := map[string][]string{
+		"App":            {"App"},
+		"DatabaseConfig": {"App", "Database"},
+		"PoolConfig":     {"App", "Database", "Pool"},
+		"CacheConfig":    {"App", "Cache"},
+		"Unrelated":      nil,
+	}
+	if diff := cmp.Diff(expected, got, ignorePos); diff != "" {
+		t.Errorf("breadcrumbs mismatch (-want +got):\n%s", diff)
+	}
+}
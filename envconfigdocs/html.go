@@ -0,0 +1,89 @@
+package envconfigdocs
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// WriteHTML writes each config type as an <h2> heading, its comments and a
+// <table> with the same columns as WriteMarkdown. Cell contents are escaped.
+func WriteHTML(w io.Writer, configs map[string]*Config, opts *MarkdownOptions) error {
+	writeHTMLLegend(w, opts)
+	withPackage := multiplePackages(configs)
+	for _, entry := range sortedConfigs(configs) {
+		config := entry.Value
+		if !opts.NoHeadings {
+			fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(sectionTitle(entry.Key, config)))
+			if withPackage && config.Package != "" {
+				fmt.Fprintf(w, "<p>Package: <code>%s</code></p>\n", html.EscapeString(config.Package))
+			}
+			for _, c := range config.Comments {
+				fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(strings.TrimSpace(c.Text())))
+			}
+			if config.Prefix != "" {
+				fmt.Fprintf(w, "<p>Environment variables are prefixed with <code>%s</code>.</p>\n", html.EscapeString(config.namePrefix()))
+			}
+		}
+		for _, group := range partitionKeys(config.Keys, opts) {
+			if group.Title != "" {
+				fmt.Fprintf(w, "<h3>%s</h3>\n", group.Title)
+			}
+			if err := writeHTMLTable(w, group.Keys, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// htmlBold matches the **bold** text of a Markdown legend.
+var htmlBold = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+
+// writeHTMLLegend writes the legend paragraph, if any, turning its
+// Markdown **bold** text into <strong> elements.
+func writeHTMLLegend(w io.Writer, opts *MarkdownOptions) {
+	if opts.Legend != "" {
+		fmt.Fprintf(w, "<p>%s</p>\n", htmlBold.ReplaceAllString(html.EscapeString(opts.Legend), "<strong>$1</strong>"))
+	}
+}
+
+// writeHTMLTable writes keys as an HTML table with the columns selected by
+// opts.
+func writeHTMLTable(w io.Writer, keys []*Key, opts *MarkdownOptions) error {
+	table := tablewriter.NewTable(w,
+		tablewriter.WithRenderer(renderer.NewHTML()),
+		tablewriter.WithConfig(tablewriter.NewConfigBuilder().
+			Header().Alignment().WithGlobal(tw.AlignLeft).Build().
+			Header().Formatting().WithAutoFormat(tw.Off).Build().Build().
+			Build()),
+	)
+	cols, err := opts.tableColumns()
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = col.Header
+	}
+	table.Header(header)
+	for _, key := range keys {
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			row[i] = col.Value(key)
+		}
+		if err := table.Append(row); err != nil {
+			return fmt.Errorf("failed to append row: %w", err)
+		}
+	}
+	if err := table.Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteHTML(t *testing.T) {
+	configs := map[string]*Config{
+		"Config": {
+			Comments: []*ast.CommentGroup{{List: []*ast.Comment{{Text: "// Config is <the> app config."}}}},
+			Keys: []*Key{
+				{Name: "GREETING", Type: "string", Default: "<hi>", Comment: "Greeting & farewell"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+
+	expected := `<h2>Config</h2>
+<p>Config is &lt;the&gt; app config.</p>
+<table>
+<thead>
+  <tr><th style="text-align: left;">Name</th><th style="text-align: left;">Type</th><th style="text-align: left;">Required</th><th style="text-align: left;">Default</th><th style="text-align: left;">Comment</th></tr>
+</thead>
+<tbody>
+  <tr><td style="text-align: left;">GREETING</td><td style="text-align: left;">string</td><td style="text-align: left;">false</td><td style="text-align: left;">&#34;&lt;hi&gt;&#34;</td><td style="text-align: left;">Greeting &amp; farewell</td></tr>
+</tbody>
+</table>
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteHTML() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteHTMLPartitionRequired(t *testing.T) {
+	configs := map[string]*Config{
+		"Config": {
+			Keys: []*Key{
+				{Name: "PORT", Type: "int", Required: true},
+				{Name: "HOST", Type: "string"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, configs, &MarkdownOptions{Partition: PartitionRequired, Columns: []string{"name"}}); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+
+	expected := `<h2>Config</h2>
+<h3>Required</h3>
+<table>
+<thead>
+  <tr><th style="text-align: left;">Name</th></tr>
+</thead>
+<tbody>
+  <tr><td style="text-align: left;">PORT</td></tr>
+</tbody>
+</table>
+<h3>Optional</h3>
+<table>
+<thead>
+  <tr><th style="text-align: left;">Name</th></tr>
+</thead>
+<tbody>
+  <tr><td style="text-align: left;">HOST</td></tr>
+</tbody>
+</table>
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteHTML() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteHTMLLegend(t *testing.T) {
+	configs := map[string]*Config{
+		"Config": {Keys: []*Key{{Name: "PORT", Type: "int"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, configs, &MarkdownOptions{Legend: "**Name** is <the> variable.", NoHeadings: true}); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<p><strong>Name</strong> is &lt;the&gt; variable.</p>\n<table>") {
+		t.Errorf("WriteHTML() does not start with the legend:\n%s", buf.String())
+	}
+}
+
+func TestWriteHTMLPackages(t *testing.T) {
+	configs := map[string]*Config{
+		"example.com/a.Config": {Package: "example.com/a", Keys: []*Key{{Name: "A", Type: "string"}}},
+		"example.com/b.Config": {Package: "example.com/b", Keys: []*Key{{Name: "B", Type: "string"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	for _, pkg := range []string{"example.com/a", "example.com/b"} {
+		if !strings.Contains(buf.String(), "<p>Package: <code>"+pkg+"</code></p>\n") {
+			t.Errorf("WriteHTML() output has no package note for %s:\n%s", pkg, buf.String())
+		}
+	}
+
+	// a single package needs no note
+	delete(configs, "example.com/b.Config")
+	buf.Reset()
+	if err := WriteHTML(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "Package:") {
+		t.Errorf("WriteHTML() output has a package note for a single package:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,44 @@
+package envconfigdocs
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes configs as an indented JSON object keyed by type name.
+// Object keys are sorted, so the output is stable across runs.
+func WriteJSON(w io.Writer, configs map[string]*Config) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(configs)
+}
+
+// MarshalJSON encodes the config type with its doc comments flattened to
+// text, one string per comment group.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	var comments []string
+	for _, group := range c.Comments {
+		comments = append(comments, strings.TrimSpace(group.Text()))
+	}
+	return json.Marshal(struct {
+		Comments   []string `json:"comments,omitempty"`
+		Package    string   `json:"package,omitempty"`
+		Prefix     string   `json:"prefix,omitempty"`
+		Breadcrumb []string `json:"breadcrumb,omitempty"`
+		Keys       []*Key   `json:"keys"`
+	}{
+		Comments:   comments,
+		Package:    c.Package,
+		Prefix:     c.Prefix,
+		Breadcrumb: c.Breadcrumb,
+		Keys:       c.Keys,
+	})
+}
+
+// MarshalJSON encodes the key, leaving out the default of a secret.
+func (k *Key) MarshalJSON() ([]byte, error) {
+	// plain has the fields of Key without its methods
+	type plain Key
+	return json.Marshal((*plain)(publicKey(k)))
+}
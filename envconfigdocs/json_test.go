@@ -0,0 +1,98 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"go/ast"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteJSON(t *testing.T) {
+	configs := map[string]*Config{
+		"ZConfig": {
+			Keys: []*Key{{Name: "Z", Type: "string"}},
+		},
+		"AConfig": {
+			Comments: []*ast.CommentGroup{{List: []*ast.Comment{{Text: "// AConfig is documented."}}}},
+			Keys: []*Key{
+				{Name: "PORT", Type: "int", Required: true, Comment: "Port to listen on"},
+				{Name: "HOST", Type: "string", Default: "localhost"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, configs); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	expected := `{
+  "AConfig": {
+    "comments": [
+      "AConfig is documented."
+    ],
+    "keys": [
+      {
+        "name": "PORT",
+        "type": "int",
+        "required": true,
+        "comment": "Port to listen on"
+      },
+      {
+        "name": "HOST",
+        "type": "string",
+        "required": false,
+        "default": "localhost"
+      }
+    ]
+  },
+  "ZConfig": {
+    "keys": [
+      {
+        "name": "Z",
+        "type": "string",
+        "required": false
+      }
+    ]
+  }
+}
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteJSONSecret(t *testing.T) {
+	configs := map[string]*Config{
+		"Config": {
+			Keys: []*Key{{Name: "TOKEN", Type: "string", Default: "s3cr3t", Secret: true}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, configs); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	expected := `{
+  "Config": {
+    "keys": [
+      {
+        "name": "TOKEN",
+        "type": "string",
+        "required": false,
+        "secret": true
+      }
+    ]
+  }
+}
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteJSON() mismatch (-want +got):\n%s", diff)
+	}
+	// the key itself keeps its default for other renderers
+	if got := configs["Config"].Keys[0].Default; got != "s3cr3t" {
+		t.Errorf("Default = %q after WriteJSON, want it unchanged", got)
+	}
+}
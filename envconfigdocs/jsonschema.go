@@ -0,0 +1,116 @@
+package envconfigdocs
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonSchemaDialect is the JSON Schema version the schemas declare.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of JSON Schema written by WriteJSONSchema.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+}
+
+// WriteJSONSchema writes an indented JSON object mapping each type name to a
+// JSON Schema describing its environment variables as the properties of an
+// object, for validation tools.
+func WriteJSONSchema(w io.Writer, configs map[string]*Config) error {
+	schemas := make(map[string]*jsonSchema, len(configs))
+	for name, config := range configs {
+		schema := &jsonSchema{
+			Schema:     jsonSchemaDialect,
+			Title:      sectionTitle(name, config),
+			Type:       "object",
+			Properties: map[string]*jsonSchema{},
+		}
+		var comments []string
+		for _, group := range config.Comments {
+			comments = append(comments, strings.TrimSpace(group.Text()))
+		}
+		schema.Description = strings.Join(comments, "\n\n")
+		for _, key := range config.Keys {
+			property := typeSchema(cmp.Or(key.Underlying, key.Type))
+			property.Description = key.Comment
+			for _, value := range key.Values {
+				property.Enum = append(property.Enum, schemaValue(property, value))
+			}
+			if key.Default != "" && !key.Secret {
+				property.Default = schemaValue(property, key.Default)
+			}
+			schema.Properties[key.Name] = property
+			if key.Required {
+				schema.Required = append(schema.Required, key.Name)
+			}
+		}
+		schemas[name] = schema
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schemas)
+}
+
+// typeSchema returns the schema of values of the Go type typ, as rendered by
+// typeString. Types it does not recognize get a schema without a type.
+func typeSchema(typ string) *jsonSchema {
+	typ = strings.TrimPrefix(typ, "*")
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return &jsonSchema{Type: "array", Items: typeSchema(typ[len("[]"):])}
+	case strings.HasPrefix(typ, "map["):
+		// envconfig reads maps as comma-separated key:value pairs
+		_, value, _ := strings.Cut(typ, "]")
+		return &jsonSchema{Type: "object", AdditionalProperties: typeSchema(value)}
+	}
+	switch typ {
+	case "string", "time.Duration":
+		return &jsonSchema{Type: "string"}
+	case "bool":
+		return &jsonSchema{Type: "boolean"}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return &jsonSchema{Type: "integer"}
+	case "float32", "float64":
+		return &jsonSchema{Type: "number"}
+	}
+	return &jsonSchema{}
+}
+
+// schemaValue converts value, as written in a tag, to a JSON value of the
+// type of schema. Arrays are split on commas, the way envconfig reads them.
+// Values that do not parse as the type are kept as strings.
+func schemaValue(schema *jsonSchema, value string) any {
+	switch schema.Type {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer":
+		if n, err := strconv.ParseInt(value, 0, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "array":
+		var items []any
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, schemaValue(schema.Items, item))
+		}
+		return items
+	}
+	return value
+}
@@ -0,0 +1,86 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"go/ast"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteJSONSchema(t *testing.T) {
+	configs := map[string]*Config{
+		"Config": {
+			Comments: []*ast.CommentGroup{{List: []*ast.Comment{{Text: "// Config is the app config."}}}},
+			Keys: []*Key{
+				{Name: "PORT", Type: "Port", Underlying: "int", Required: true, Default: "8080", Comment: "Port to listen on"},
+				{Name: "DEBUG", Type: "bool", Default: "true"},
+				{Name: "HOSTS", Type: "[]string", Default: "a,b"},
+				{Name: "LEVEL", Type: "LogLevel", Underlying: "string", Values: []string{"debug", "info"}},
+				{Name: "LABELS", Type: "map[string]int"},
+				{Name: "RATIO", Type: "*float64", Required: true},
+				{Name: "URL", Type: "url.URL"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONSchema(&buf, configs); err != nil {
+		t.Fatalf("WriteJSONSchema failed: %v", err)
+	}
+
+	expected := `{
+  "Config": {
+    "$schema": "https://json-schema.org/draft/2020-12/schema",
+    "title": "Config",
+    "description": "Config is the app config.",
+    "type": "object",
+    "properties": {
+      "DEBUG": {
+        "type": "boolean",
+        "default": true
+      },
+      "HOSTS": {
+        "type": "array",
+        "items": {
+          "type": "string"
+        },
+        "default": [
+          "a",
+          "b"
+        ]
+      },
+      "LABELS": {
+        "type": "object",
+        "additionalProperties": {
+          "type": "integer"
+        }
+      },
+      "LEVEL": {
+        "type": "string",
+        "enum": [
+          "debug",
+          "info"
+        ]
+      },
+      "PORT": {
+        "description": "Port to listen on",
+        "type": "integer",
+        "default": 8080
+      },
+      "RATIO": {
+        "type": "number"
+      },
+      "URL": {}
+    },
+    "required": [
+      "PORT",
+      "RATIO"
+    ]
+  }
+}
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteJSONSchema() mismatch (-want +got):\n%s", diff)
+	}
+}
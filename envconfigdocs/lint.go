@@ -0,0 +1,232 @@
+package envconfigdocs
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// CheckTagTypos reports struct tag keys in pkg that look like misspellings
+// of a tag key known to the tag conventions selected by opts, or of the
+// description and secret tags it selects.
+func CheckTagTypos(pkg *packages.Package, opts *CollectOptions) []string {
+	known := slices.Concat(opts.tagStyle().Keys, opts.descTags(), []string{opts.secretTag()})
+	return tagTypos(collectDecls(pkg.Syntax), known)
+}
+
+// tagTypos reports struct tag keys that look like misspellings of one of
+// known, e.g. `requird:"true"` or `defualt:"x"`.
+func tagTypos(decls map[string]*decl, known []string) []string {
+	var warnings []string
+	for _, name := range slices.Sorted(maps.Keys(decls)) {
+		for _, field := range decls[name].Fields {
+			if field.Tag == nil || field.Tag.Value == "" {
+				continue
+			}
+			for _, key := range tagKeys(structTag(field)) {
+				suggestion, ok := suggestTagKey(key, known)
+				if !ok {
+					continue
+				}
+				warnings = append(warnings, fmt.Sprintf("%s.%s: tag key %q looks like a typo of %q", name, fieldName(field), key, suggestion))
+			}
+		}
+	}
+	return warnings
+}
+
+// CheckNameConvention reports environment variable names that do not match
+// the naming convention re.
+func CheckNameConvention(configs map[string]*Config, re *regexp.Regexp) []string {
+	var warnings []string
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		for _, key := range configs[name].Keys {
+			if !re.MatchString(key.Name) {
+				warnings = append(warnings, fmt.Sprintf("%s: %s does not match naming convention %q", name, key.Name, re))
+			}
+		}
+	}
+	return warnings
+}
+
+// CheckDefaults reports defaults that envconfig would fail to parse as the
+// declared type of their key.
+func CheckDefaults(configs map[string]*Config) []string {
+	var problems []string
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		for _, key := range configs[name].Keys {
+			if key.Default == "" {
+				continue
+			}
+			if err := parseDefault(cmp.Or(key.Underlying, key.Type), key.Default); err != nil {
+				problems = append(problems, fmt.Sprintf("%s.%s: default %q is not a valid %s", name, key.Name, key.Default, key.Type))
+			}
+		}
+	}
+	return problems
+}
+
+// CheckDocs reports required keys without a description, from either a
+// comment or a description tag.
+func CheckDocs(configs map[string]*Config) []string {
+	var problems []string
+	for _, name := range slices.Sorted(maps.Keys(configs)) {
+		for _, key := range configs[name].Keys {
+			if key.Required && strings.TrimSpace(key.Comment) == "" {
+				problems = append(problems, fmt.Sprintf("%s.%s: required but undocumented", name, key.Name))
+			}
+		}
+	}
+	return problems
+}
+
+// parseDefault parses value as envconfig would for a field of type typ.
+// Types it does not know, such as named types, are accepted as is.
+func parseDefault(typ, value string) error {
+	typ = strings.TrimPrefix(typ, "*")
+	if elem, ok := strings.CutPrefix(typ, "[]"); ok {
+		// envconfig splits slices on commas
+		for _, v := range strings.Split(value, ",") {
+			if err := parseDefault(elem, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	switch typ {
+	case "time.Duration":
+		_, err = time.ParseDuration(value)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int", "int8", "int16", "int32", "int64":
+		_, err = strconv.ParseInt(value, 0, bitSize(typ, "int"))
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		_, err = strconv.ParseUint(value, 0, bitSize(typ, "uint"))
+	case "byte":
+		_, err = strconv.ParseUint(value, 0, 8)
+	case "float32", "float64":
+		_, err = strconv.ParseFloat(value, bitSize(typ, "float"))
+	}
+	return err
+}
+
+// bitSize returns the size in bits of the numeric type typ named kind
+// followed by an optional size, e.g. 32 for int32 and 0 for int.
+func bitSize(typ, kind string) int {
+	size, err := strconv.Atoi(strings.TrimPrefix(typ, kind))
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// suggestTagKey returns the known tag key closest to key when key is not
+// itself known but is within a small edit distance of one.
+func suggestTagKey(key string, knownKeys []string) (string, bool) {
+	if slices.Contains(knownKeys, key) {
+		return "", false
+	}
+	best, bestDist := "", -1
+	for _, known := range knownKeys {
+		maxDist := 2
+		if len(known) <= 4 {
+			maxDist = 1
+		}
+		d := editDistance(key, known)
+		if d > maxDist {
+			continue
+		}
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = known, d
+		}
+	}
+	return best, bestDist > 0
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b, counting insertions, deletions, substitutions and transpositions of
+// adjacent characters as one edit each.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// tagKeys returns the keys of tag in the order they appear, following the
+// conventional `key:"value"` syntax understood by reflect.StructTag.
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := string(tag[:i])
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		keys = append(keys, key)
+		tag = tag[i+1:]
+	}
+	return keys
+}
+
+// fieldName returns a printable name for field, using the type for
+// embedded fields.
+func fieldName(field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return types.ExprString(field.Type)
+	}
+	return field.Names[0].Name
+}
@@ -0,0 +1,149 @@
+package envconfigdocs
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckTagTypos(t *testing.T) {
+	source := `
+package test
+
+type MyConfig struct {
+	Host    string ` + "`envconfig:\"HOST\" requird:\"true\"`" + `
+	Port    int    ` + "`envconfig:\"PORT\" defualt:\"8080\"`" + `
+	Name    string ` + "`envconfig:\"NAME\" json:\"name\" desc:\"the name\"`" + `
+	Verbose bool   ` + "`envconfg:\"VERBOSE\"`" + `
+}
+`
+	warnings := tagTypos(collectDecls(parseTestPackage(t, source).Syntax), tagStyles["kelsey"].Keys)
+
+	expected := []string{
+		`MyConfig.Host: tag key "requird" looks like a typo of "required"`,
+		`MyConfig.Port: tag key "defualt" looks like a typo of "default"`,
+		`MyConfig.Verbose: tag key "envconfg" looks like a typo of "envconfig"`,
+	}
+	if diff := cmp.Diff(expected, warnings); diff != "" {
+		t.Errorf("tagTypos() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckTagTyposCustomTags(t *testing.T) {
+	source := `
+package test
+
+type MyConfig struct {
+	Host     string ` + "`envconfig:\"HOST\" descr:\"the host\"`" + `
+	Port     int    ` + "`envconfig:\"PORT\" dsecr:\"the port\"`" + `
+	Password string ` + "`envconfig:\"PASSWORD\" secert:\"true\"`" + `
+}
+`
+	// descr is the description tag, not a typo of desc
+	warnings := CheckTagTypos(parseTestPackage(t, source), &CollectOptions{DescTag: "descr"})
+
+	expected := []string{
+		`MyConfig.Port: tag key "dsecr" looks like a typo of "descr"`,
+		`MyConfig.Password: tag key "secert" looks like a typo of "secret"`,
+	}
+	if diff := cmp.Diff(expected, warnings); diff != "" {
+		t.Errorf("CheckTagTypos() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTagKeys(t *testing.T) {
+	got := tagKeys(`envconfig:"NAME" default:"a \"quoted\" value" required:"true"`)
+	expected := []string{"envconfig", "default", "required"}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("tagKeys() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckNameConvention(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{
+				{Name: "DATABASE_URL", Type: "string"},
+				{Name: "apiKey", Type: "string"},
+			},
+		},
+		"DBConfig": {
+			Keys: []*Key{
+				{Name: "DB-HOST", Type: "string"},
+			},
+		},
+	}
+
+	warnings := CheckNameConvention(configs, regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`))
+
+	expected := []string{
+		`AppConfig: apiKey does not match naming convention "^[A-Z][A-Z0-9_]*$"`,
+		`DBConfig: DB-HOST does not match naming convention "^[A-Z][A-Z0-9_]*$"`,
+	}
+	if diff := cmp.Diff(expected, warnings); diff != "" {
+		t.Errorf("CheckNameConvention() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckDocs(t *testing.T) {
+	configs := map[string]*Config{
+		"MyConfig": {
+			Keys: []*Key{
+				{Name: "HOST", Type: "string", Required: true, Comment: "Host to bind"},
+				{Name: "PORT", Type: "int", Required: true},
+				{Name: "DEBUG", Type: "bool"},
+				{Name: "TOKEN", Type: "string", Required: true, Comment: " "},
+			},
+		},
+		"AppConfig": {
+			Keys: []*Key{
+				{Name: "NAME", Type: "string", Required: true},
+			},
+		},
+	}
+
+	problems := CheckDocs(configs)
+
+	expected := []string{
+		"AppConfig.NAME: required but undocumented",
+		"MyConfig.PORT: required but undocumented",
+		"MyConfig.TOKEN: required but undocumented",
+	}
+	if diff := cmp.Diff(expected, problems); diff != "" {
+		t.Errorf("CheckDocs() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckDefaults(t *testing.T) {
+	configs := map[string]*Config{
+		"MyConfig": {
+			Keys: []*Key{
+				{Name: "HOST", Type: "string", Default: "localhost"},
+				{Name: "PORT", Type: "int", Default: "abc"},
+				{Name: "RETRIES", Type: "uint8", Default: "300"},
+				{Name: "DEBUG", Type: "bool", Default: "yes"},
+				{Name: "RATIO", Type: "float64", Default: "0.5"},
+				{Name: "TIMEOUT", Type: "time.Duration", Default: "30"},
+				{Name: "INTERVAL", Type: "*time.Duration", Default: "1m30s"},
+				{Name: "PORTS", Type: "[]int", Default: "80,x"},
+				{Name: "MASK", Type: "int", Default: "0x1f"},
+				{Name: "LEVEL", Type: "Level", Default: "anything"},
+				{Name: "EMPTY", Type: "int"},
+			},
+		},
+	}
+
+	problems := CheckDefaults(configs)
+
+	expected := []string{
+		`MyConfig.PORT: default "abc" is not a valid int`,
+		`MyConfig.RETRIES: default "300" is not a valid uint8`,
+		`MyConfig.DEBUG: default "yes" is not a valid bool`,
+		`MyConfig.TIMEOUT: default "30" is not a valid time.Duration`,
+		`MyConfig.PORTS: default "80,x" is not a valid []int`,
+	}
+	if diff := cmp.Diff(expected, problems); diff != "" {
+		t.Errorf("CheckDefaults() mismatch (-want +got):\n%s", diff)
+	}
+}
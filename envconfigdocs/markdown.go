@@ -0,0 +1,581 @@
+package envconfigdocs
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// MarkdownOptions controls how WriteMarkdown renders the collected configs.
+type MarkdownOptions struct {
+	// NoHeadings omits the per-type headings and type comments so that only
+	// the tables are emitted.
+	NoHeadings bool
+	// WithFlags adds a Flag column holding the command-line flag derived
+	// from each environment variable name.
+	WithFlags bool
+	// Legend is a paragraph explaining the columns, written once before the
+	// first config type.
+	Legend string
+	// Partition splits the keys of each type into titled groups. The only
+	// supported value is PartitionRequired.
+	Partition string
+	// HeadingLevel is the level of the per-type headings, from 1 to 6. The
+	// default is 2; sub-section headings are one level deeper.
+	HeadingLevel int
+	// Compact renders Markdown tables without padding cells to a common
+	// width.
+	Compact bool
+	// TOC writes a list of links to the sections of the config types before
+	// the first one.
+	TOC bool
+	// NoComments leaves out the comment column and the comments of keys.
+	NoComments bool
+	// RawDefaults renders every default value as written in its tag,
+	// without quotes.
+	RawDefaults bool
+	// ShowEmptyDefault renders the default of keys without one as (none),
+	// and explicitly empty defaults as "", so that readers can tell them
+	// apart.
+	ShowEmptyDefault bool
+	// Columns names the table columns to render, in order: name, flag,
+	// type, required, default, comment or source. The default is name,
+	// type, required, default and comment.
+	Columns []string
+	// Headers overrides the headers of the rendered columns, in order. It
+	// must have one header per column.
+	Headers []string
+}
+
+// Validate reports options that name an unknown partition or column, whose
+// headers do not match the columns, or whose heading level is out of range.
+func (opts *MarkdownOptions) Validate() error {
+	if opts.Partition != "" && opts.Partition != PartitionRequired {
+		return fmt.Errorf("unsupported partition %q", opts.Partition)
+	}
+	if _, err := opts.tableColumns(); err != nil {
+		return fmt.Errorf("invalid columns: %w", err)
+	}
+	if opts.HeadingLevel < 0 || opts.HeadingLevel > 6 {
+		return fmt.Errorf("heading level %d is out of range 1-6", opts.HeadingLevel)
+	}
+	return nil
+}
+
+// heading returns the Markdown heading marker depth levels below the
+// per-type headings, capped at the deepest level Markdown has.
+func (opts *MarkdownOptions) heading(depth int) string {
+	level := opts.HeadingLevel
+	if level == 0 {
+		level = 2
+	}
+	return strings.Repeat("#", min(level+depth, 6))
+}
+
+// column is a table column selectable with MarkdownOptions.Columns.
+type column struct {
+	Header string
+	Value  func(key *Key) string
+}
+
+// columns maps the names accepted in MarkdownOptions.Columns to their
+// columns.
+var columns = map[string]*column{
+	"name":     {Header: "Name", Value: func(key *Key) string { return key.Name }},
+	"flag":     {Header: "Flag", Value: func(key *Key) string { return flagName(key.Name) }},
+	"type":     {Header: "Type", Value: formatType},
+	"required": {Header: "Required", Value: formatRequired},
+	"default":  {Header: "Default", Value: formatDefault},
+	"comment":  {Header: "Comment", Value: formatComment},
+	"source":   {Header: "Source", Value: func(key *Key) string { return key.Pos }},
+}
+
+// defaultColumns are the columns rendered when MarkdownOptions.Columns is
+// empty.
+var defaultColumns = []string{"name", "type", "required", "default", "comment"}
+
+// tableColumns returns the columns selected by opts, titled with opts.Headers
+// if given. WithFlags adds the flag column after the first one unless it is
+// already selected, and NoComments removes the comment column.
+func (opts *MarkdownOptions) tableColumns() ([]*column, error) {
+	names := opts.Columns
+	if len(names) == 0 {
+		names = defaultColumns
+	}
+	if opts.WithFlags && !slices.Contains(names, "flag") {
+		names = slices.Insert(slices.Clone(names), min(1, len(names)), "flag")
+	}
+	if opts.NoComments {
+		names = slices.DeleteFunc(slices.Clone(names), func(name string) bool { return name == "comment" })
+	}
+	var cols []*column
+	for _, name := range names {
+		col, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q, expected one of %s", name, strings.Join(slices.Sorted(maps.Keys(columns)), ", "))
+		}
+		if name == "default" {
+			col = &column{Header: col.Header, Value: opts.defaultOf}
+		}
+		cols = append(cols, col)
+	}
+	if len(opts.Headers) == 0 {
+		return cols, nil
+	}
+	if len(opts.Headers) != len(cols) {
+		return nil, fmt.Errorf("got %d headers for %d columns", len(opts.Headers), len(cols))
+	}
+	for i, col := range cols {
+		cols[i] = &column{Header: opts.Headers[i], Value: col.Value}
+	}
+	return cols, nil
+}
+
+// PartitionRequired partitions keys into Required and Optional groups.
+const PartitionRequired = "required"
+
+// DefaultLegend is a legend for MarkdownOptions.Legend describing the
+// default columns.
+const DefaultLegend = "Each table lists the environment variables read by a configuration type. " +
+	"**Name** is the environment variable to set, " +
+	"**Type** is the Go type its value is parsed as, " +
+	"**Required** tells whether the application refuses to start when the variable is unset, " +
+	"**Default** is the value used when the variable is unset, " +
+	"and **Comment** describes what the variable controls. " +
+	"Variables are read from the process environment when the application starts."
+
+// flagName derives the kebab-cased command-line flag corresponding to the
+// environment variable name, e.g. DATABASE_URL becomes --database-url.
+func flagName(name string) string {
+	return "--" + strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+// sortedConfigs returns the entries of configs sorted by section title, so
+// that types with breadcrumbs follow their parents.
+func sortedConfigs(configs map[string]*Config) []*entry[string, *Config] {
+	return slices.SortedFunc(entries(maps.All(configs)), func(a, b *entry[string, *Config]) int {
+		return strings.Compare(sectionTitle(a.Key, a.Value), sectionTitle(b.Key, b.Value))
+	})
+}
+
+// sectionTitle returns the heading of the section documenting the config
+// type called name: its breadcrumb when it has one, or else its name.
+func sectionTitle(name string, config *Config) string {
+	if config.Breadcrumb != nil {
+		return strings.Join(config.Breadcrumb, " > ")
+	}
+	return name
+}
+
+// multiplePackages reports whether configs were collected from more than
+// one package, in which case their headings alone can be ambiguous.
+func multiplePackages(configs map[string]*Config) bool {
+	var first string
+	for _, config := range configs {
+		if config.Package == "" {
+			continue
+		}
+		if first == "" {
+			first = config.Package
+		} else if config.Package != first {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMarkdownSection writes the heading, comments and prefix note that
+// precede the keys of a config type. withPackage adds the import path of the
+// type below the heading.
+func writeMarkdownSection(w io.Writer, name string, config *Config, opts *MarkdownOptions, withPackage bool) {
+	if opts.NoHeadings {
+		return
+	}
+
+	fmt.Fprintf(w, "%s %s\n\n", opts.heading(0), sectionTitle(name, config))
+
+	if withPackage && config.Package != "" {
+		fmt.Fprintf(w, "Package: `%s`\n\n", config.Package)
+	}
+
+	// comments are Markdown already: lists, code blocks and tables are kept
+	// as written, and each ends with a blank line so that a trailing table
+	// does not run into the next one
+	for _, c := range config.Comments {
+		if text := docText(c); text != "" {
+			fmt.Fprintf(w, "%s\n", text)
+		}
+	}
+
+	if config.Prefix != "" {
+		fmt.Fprintf(w, "Environment variables are prefixed with `%s`.\n\n", config.namePrefix())
+	}
+}
+
+// docText returns the text of the type comment c as written, with blank
+// lines and indentation preserved. The leading asterisks of block comments
+// in the /* * */ style are dropped along with the space after them.
+func docText(c *ast.CommentGroup) string {
+	text := c.Text()
+	if len(c.List) > 0 && strings.HasPrefix(c.List[0].Text, "/*") {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			if rest, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), "*"); ok {
+				lines[i] = strings.TrimPrefix(rest, " ")
+			}
+		}
+		text = strings.Join(lines, "\n")
+	}
+	return text
+}
+
+// markdownCell escapes s for use in a Markdown table cell or list item:
+// pipes, which would end the cell, are backslash-escaped and line breaks
+// become <br>. Backticks are kept so that code spans still render.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", "<br>").Replace(s)
+}
+
+// formatType returns the type of key as rendered in the docs, followed by
+// its underlying type if known, e.g. Port (int).
+func formatType(key *Key) string {
+	if key.Underlying == "" {
+		return key.Type
+	}
+	return fmt.Sprintf("%s (%s)", key.Type, key.Underlying)
+}
+
+// formatRequired returns whether key is required as rendered in the docs.
+// envconfig only enforces required when there is no default, so required
+// keys with a default are marked as such.
+func formatRequired(key *Key) string {
+	if key.Required && key.Default != "" {
+		return "true (overridden by default)"
+	}
+	return strconv.FormatBool(key.Required)
+}
+
+// formatComment returns the comment of key as rendered in the docs,
+// followed by its allowed values if known.
+func formatComment(key *Key) string {
+	comment := secretComment(key)
+	if len(key.Values) == 0 {
+		return comment
+	}
+	values := "Allowed values: `" + strings.Join(key.Values, "`, `") + "`"
+	if comment == "" {
+		return values
+	}
+	return comment + "\n" + values
+}
+
+// secretLock marks the comments of secret keys.
+const secretLock = "🔒"
+
+// secretComment returns the comment of key, starting with a lock when the
+// key holds a secret.
+func secretComment(key *Key) string {
+	if !key.Secret {
+		return key.Comment
+	}
+	return strings.TrimSpace(secretLock + " " + key.Comment)
+}
+
+// redacted replaces the defaults of secret keys in the docs.
+const redacted = "(redacted)"
+
+// formatDefault returns the default value of key as rendered in the docs:
+// bare for numeric and boolean types, so that 10 and true read as values of
+// their type, and quoted otherwise. Slices and maps are split the way
+// envconfig reads them, e.g. [a, b, c] for a,b,c and {a: 1, b: 2} for
+// a:1,b:2, so that they read as several values.
+func formatDefault(key *Key) string {
+	if key.Default == "" {
+		return ""
+	}
+	typ := strings.TrimPrefix(cmp.Or(key.Underlying, key.Type), "*")
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return "[" + strings.Join(strings.Split(key.Default, ","), ", ") + "]"
+	case strings.HasPrefix(typ, "map["):
+		pairs := strings.Split(key.Default, ",")
+		for i, pair := range pairs {
+			if k, v, ok := strings.Cut(pair, ":"); ok {
+				pairs[i] = k + ": " + v
+			}
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case isNumericOrBool(typ):
+		return key.Default
+	}
+	return fmt.Sprintf("%q", key.Default)
+}
+
+// isNumericOrBool reports whether typ names a predeclared numeric or boolean
+// type.
+func isNumericOrBool(typ string) bool {
+	obj, ok := types.Universe.Lookup(typ).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	basic, ok := obj.Type().(*types.Basic)
+	return ok && basic.Info()&(types.IsNumeric|types.IsBoolean) != 0
+}
+
+// noDefault is the default rendered for keys without one when
+// ShowEmptyDefault is set.
+const noDefault = "(none)"
+
+// defaultOf returns the default value of key as rendered with opts.
+func (opts *MarkdownOptions) defaultOf(key *Key) string {
+	if key.Secret && key.Default != "" {
+		return redacted
+	}
+	if opts.ShowEmptyDefault && key.Default == "" {
+		if key.EmptyDefault {
+			return `""`
+		}
+		return noDefault
+	}
+	if opts.RawDefaults {
+		return key.Default
+	}
+	return formatDefault(key)
+}
+
+// writeTOC writes a bulleted list of links to the section of each config
+// type when opts.TOC is set and the sections have headings.
+func writeTOC(w io.Writer, configs map[string]*Config, opts *MarkdownOptions) {
+	if !opts.TOC || opts.NoHeadings {
+		return
+	}
+	// GitHub numbers repeated anchors: config, config-1, config-2, ...
+	seen := map[string]int{}
+	for _, entry := range sortedConfigs(configs) {
+		title := sectionTitle(entry.Key, entry.Value)
+		anchor := githubAnchor(title)
+		if n := seen[anchor]; n > 0 {
+			seen[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, n)
+		} else {
+			seen[anchor] = 1
+		}
+		fmt.Fprintf(w, "- [%s](#%s)\n", title, anchor)
+	}
+	fmt.Fprintln(w)
+}
+
+// githubAnchor returns the anchor GitHub generates for a heading titled
+// title: lowercased, with spaces turned into hyphens and punctuation other
+// than hyphens and underscores dropped.
+func githubAnchor(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// writeLegend writes the legend paragraph, if any.
+func writeLegend(w io.Writer, opts *MarkdownOptions) {
+	if opts.Legend != "" {
+		fmt.Fprintf(w, "%s\n\n", opts.Legend)
+	}
+}
+
+// WriteMarkdown writes configs as Markdown: a table of keys per type, in
+// order of section title, each under a heading and the type's doc comment.
+func WriteMarkdown(w io.Writer, configs map[string]*Config, opts *MarkdownOptions) error {
+	writeLegend(w, opts)
+	writeTOC(w, configs, opts)
+	withPackage := multiplePackages(configs)
+	for _, entry := range sortedConfigs(configs) {
+		name := entry.Key
+		config := entry.Value
+
+		// write markdown
+		writeMarkdownSection(w, name, config, opts, withPackage)
+
+		for _, group := range partitionKeys(config.Keys, opts) {
+			if group.Title != "" {
+				fmt.Fprintf(w, "%s %s\n\n", opts.heading(1), group.Title)
+			}
+			if err := writeMarkdownTable(w, group.Keys, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeMarkdownTable writes keys as a Markdown table followed by a blank
+// line.
+func writeMarkdownTable(w io.Writer, keys []*Key, opts *MarkdownOptions) error {
+	if opts.Compact {
+		return writeCompactMarkdownTable(w, keys, opts)
+	}
+	table := tablewriter.NewTable(w,
+		tablewriter.WithRenderer(renderer.NewMarkdown()),
+		tablewriter.WithConfig(tablewriter.NewConfigBuilder().
+			Header().Alignment().WithGlobal(tw.AlignLeft).Build().
+			Header().Formatting().WithAutoFormat(tw.Off).Build().Build().
+			Build()),
+	)
+
+	cols, err := opts.tableColumns()
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = col.Header
+	}
+	table.Header(header)
+	for _, key := range keys {
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			row[i] = markdownCell(col.Value(key))
+		}
+		err := table.Append(row)
+		if err != nil {
+			return fmt.Errorf("failed to append row: %w", err)
+		}
+	}
+	if err := table.Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+
+	fmt.Fprintln(w)
+	return nil
+}
+
+// writeCompactMarkdownTable writes keys as a Markdown table whose cells are
+// not padded, followed by a blank line.
+func writeCompactMarkdownTable(w io.Writer, keys []*Key, opts *MarkdownOptions) error {
+	cols, err := opts.tableColumns()
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(cols))
+	delimiter := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = col.Header
+		delimiter[i] = "---"
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(w, "| %s |\n", strings.Join(delimiter, " | "))
+	for _, key := range keys {
+		row := make([]string, len(cols))
+		for i, col := range cols {
+			row[i] = markdownCell(col.Value(key))
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | "))
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// keyGroup is a titled subset of the keys of a config type.
+type keyGroup struct {
+	Title string
+	Keys  []*Key
+}
+
+// partitionKeys splits keys into the groups selected by opts.Partition,
+// dropping empty groups. Without partitioning it returns a single untitled
+// group holding all keys.
+func partitionKeys(keys []*Key, opts *MarkdownOptions) []keyGroup {
+	if opts.Partition != PartitionRequired {
+		return []keyGroup{{Keys: keys}}
+	}
+	groups := []keyGroup{{Title: "Required"}, {Title: "Optional"}}
+	for _, key := range keys {
+		if key.Required {
+			groups[0].Keys = append(groups[0].Keys, key)
+		} else {
+			groups[1].Keys = append(groups[1].Keys, key)
+		}
+	}
+	return slices.DeleteFunc(groups, func(g keyGroup) bool {
+		return len(g.Keys) == 0
+	})
+}
+
+// WriteMarkdownList writes each key as a bold name followed by a bullet
+// list of its details, which stays readable when comments are long.
+func WriteMarkdownList(w io.Writer, configs map[string]*Config, opts *MarkdownOptions) error {
+	writeLegend(w, opts)
+	writeTOC(w, configs, opts)
+	withPackage := multiplePackages(configs)
+	for _, entry := range sortedConfigs(configs) {
+		writeMarkdownSection(w, entry.Key, entry.Value, opts, withPackage)
+
+		for _, group := range partitionKeys(entry.Value.Keys, opts) {
+			if group.Title != "" {
+				fmt.Fprintf(w, "%s %s\n\n", opts.heading(1), group.Title)
+			}
+			for _, key := range group.Keys {
+				fmt.Fprintf(w, "**%s**\n\n", key.Name)
+				if opts.WithFlags {
+					fmt.Fprintf(w, "- Flag: %s\n", flagName(key.Name))
+				}
+				fmt.Fprintf(w, "- Type: %s\n", formatType(key))
+				fmt.Fprintf(w, "- Required: %s\n", formatRequired(key))
+				if key.Default != "" || opts.ShowEmptyDefault {
+					fmt.Fprintf(w, "- Default: %s\n", opts.defaultOf(key))
+				}
+				if comment := secretComment(key); comment != "" && !opts.NoComments {
+					fmt.Fprintf(w, "- Comment: %s\n", markdownCell(comment))
+				}
+				if len(key.Values) > 0 {
+					fmt.Fprintf(w, "- Values: `%s`\n", markdownCell(strings.Join(key.Values, "`, `")))
+				}
+				fmt.Fprintln(w)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteMustSet writes a sorted list of the variables that are required and
+// have no default, i.e. those that make the application fail to start when
+// unset.
+func WriteMustSet(w io.Writer, configs map[string]*Config, opts *MarkdownOptions) error {
+	writeLegend(w, opts)
+	// a variable shared by several types keeps the first non-empty comment
+	comments := map[string]string{}
+	for _, entry := range sortedConfigs(configs) {
+		for _, key := range entry.Value.Keys {
+			if !key.Required || key.Default != "" {
+				continue
+			}
+			if comments[key.Name] == "" {
+				comments[key.Name] = key.Comment
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(comments)) {
+		if comments[name] == "" {
+			fmt.Fprintf(w, "- `%s`\n", name)
+		} else {
+			fmt.Fprintf(w, "- `%s`: %s\n", name, markdownCell(comments[name]))
+		}
+	}
+	return nil
+}
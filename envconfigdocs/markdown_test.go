@@ -0,0 +1,795 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	configs := map[string]*Config{
+		"TestConfig": {
+			Keys: []*Key{
+				{Name: "Key1", Type: "string", Required: true, Default: "default1", Comment: "This is key 1"},
+				{Name: "Key2", Type: "int", Required: false, Default: "0", Comment: "This is key 2"},
+			},
+			Comments: []*ast.CommentGroup{
+				{List: []*ast.Comment{{Text: "// This is a test config"}}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := `## TestConfig
+
+This is a test config
+
+| Name | Type   | Required                     | Default    | Comment       |
+|:-----|:-------|:-----------------------------|:-----------|:--------------|
+| Key1 | string | true (overridden by default) | "default1" | This is key 1 |
+| Key2 | int    | false                        | 0          | This is key 2 |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownNoHeadings(t *testing.T) {
+	configs := map[string]*Config{
+		"TestConfig": {
+			Keys: []*Key{
+				{Name: "Key1", Type: "string", Required: true, Comment: "This is key 1"},
+			},
+			Comments: []*ast.CommentGroup{
+				{List: []*ast.Comment{{Text: "// This is a test config"}}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{NoHeadings: true}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := `| Name | Type   | Required | Default | Comment       |
+|:-----|:-------|:---------|:--------|:--------------|
+| Key1 | string | true     |         | This is key 1 |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownPrefix(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys:   []*Key{{Name: "MYAPP_PORT", Type: "int"}},
+			Prefix: "myapp",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := "## AppConfig\n\n" +
+		"Environment variables are prefixed with `MYAPP_`.\n\n" +
+		`| Name       | Type | Required | Default | Comment |
+|:-----------|:-----|:---------|:--------|:--------|
+| MYAPP_PORT | int  | false    |         |         |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownWithFlags(t *testing.T) {
+	configs := map[string]*Config{
+		"TestConfig": {
+			Keys: []*Key{
+				{Name: "DATABASE_URL", Type: "string", Required: true, Comment: "Database URL"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{WithFlags: true}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := `## TestConfig
+
+| Name         | Flag           | Type   | Required | Default | Comment      |
+|:-------------|:---------------|:-------|:---------|:--------|:-------------|
+| DATABASE_URL | --database-url | string | true     |         | Database URL |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownList(t *testing.T) {
+	configs := map[string]*Config{
+		"TestConfig": {
+			Keys: []*Key{
+				{Name: "Key1", Type: "string", Required: true, Default: "default1", Comment: "This is key 1"},
+				{Name: "Key2", Type: "int"},
+			},
+			Comments: []*ast.CommentGroup{
+				{List: []*ast.Comment{{Text: "// This is a test config"}}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownList(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteMarkdownList failed: %v", err)
+	}
+
+	expected := `## TestConfig
+
+This is a test config
+
+**Key1**
+
+- Type: string
+- Required: true (overridden by default)
+- Default: "default1"
+- Comment: This is key 1
+
+**Key2**
+
+- Type: int
+- Required: false
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdownList output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownLegend(t *testing.T) {
+	configs := map[string]*Config{
+		"TestConfig": {
+			Keys: []*Key{{Name: "Key1", Type: "string"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{Legend: "Set these variables before starting the app."}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := `Set these variables before starting the app.
+
+## TestConfig
+
+| Name | Type   | Required | Default | Comment |
+|:-----|:-------|:---------|:--------|:--------|
+| Key1 | string | false    |         |         |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownPartitionRequired(t *testing.T) {
+	configs := map[string]*Config{
+		"TestConfig": {
+			Keys: []*Key{
+				{Name: "Key1", Type: "string", Required: true},
+				{Name: "Key2", Type: "int", Default: "1"},
+				{Name: "Key3", Type: "bool", Required: true},
+			},
+		},
+		"OptionalConfig": {
+			Keys: []*Key{
+				{Name: "Key4", Type: "string"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{Partition: PartitionRequired}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := `## OptionalConfig
+
+### Optional
+
+| Name | Type   | Required | Default | Comment |
+|:-----|:-------|:---------|:--------|:--------|
+| Key4 | string | false    |         |         |
+
+## TestConfig
+
+### Required
+
+| Name | Type   | Required | Default | Comment |
+|:-----|:-------|:---------|:--------|:--------|
+| Key1 | string | true     |         |         |
+| Key3 | bool   | true     |         |         |
+
+### Optional
+
+| Name | Type | Required | Default | Comment |
+|:-----|:-----|:---------|:--------|:--------|
+| Key2 | int  | false    | 1       |         |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMustSet(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{
+				{Name: "PORT", Type: "int", Required: true, Default: "8080"},
+				{Name: "DATABASE_URL", Type: "string", Required: true, Comment: "Database URL"},
+				{Name: "DEBUG", Type: "bool"},
+			},
+		},
+		"WorkerConfig": {
+			Keys: []*Key{
+				{Name: "API_KEY", Type: "string", Required: true},
+				{Name: "DATABASE_URL", Type: "string", Required: true},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMustSet(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteMustSet failed: %v", err)
+	}
+
+	expected := "- `API_KEY`\n" +
+		"- `DATABASE_URL`: Database URL\n"
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMustSet output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownBreadcrumbs(t *testing.T) {
+	configs := map[string]*Config{
+		"App": {
+			Keys:       []*Key{{Name: "NAME", Type: "string"}},
+			Breadcrumb: []string{"App"},
+		},
+		"PoolConfig": {
+			Keys:       []*Key{{Name: "POOL_SIZE", Type: "int"}},
+			Breadcrumb: []string{"App", "Database", "Pool"},
+		},
+		"Another": {
+			Keys: []*Key{{Name: "VALUE", Type: "string"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownList(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteMarkdownList failed: %v", err)
+	}
+
+	expected := `## Another
+
+**VALUE**
+
+- Type: string
+- Required: false
+
+## App
+
+**NAME**
+
+- Type: string
+- Required: false
+
+## App > Database > Pool
+
+**POOL_SIZE**
+
+- Type: int
+- Required: false
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdownList output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownPackages(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys:    []*Key{{Name: "PORT", Type: "int"}},
+			Package: "example.com/app",
+		},
+		"DBConfig": {
+			Keys:    []*Key{{Name: "DB_HOST", Type: "string"}},
+			Package: "example.com/db",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := "## AppConfig\n\n" +
+		"Package: `example.com/app`\n\n" +
+		`| Name | Type | Required | Default | Comment |
+|:-----|:-----|:---------|:--------|:--------|
+| PORT | int  | false    |         |         |
+
+` + "## DBConfig\n\n" +
+		"Package: `example.com/db`\n\n" +
+		`| Name    | Type   | Required | Default | Comment |
+|:--------|:-------|:---------|:--------|:--------|
+| DB_HOST | string | false    |         |         |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+
+	// a single package needs no qualification
+	configs["DBConfig"].Package = "example.com/app"
+	buf.Reset()
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "Package:") {
+		t.Errorf("WriteMarkdown output mentions the package of a single-package run:\n%s", buf.String())
+	}
+}
+
+func TestWriteMarkdownColumns(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "PORT", Type: "int", Required: true, Default: "8080", Comment: "Port to listen on"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{NoHeadings: true, Columns: []string{"default", "name"}}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := `| Default | Name |
+|:--------|:-----|
+| 8080    | PORT |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+
+	err := WriteMarkdown(&bytes.Buffer{}, configs, &MarkdownOptions{Columns: []string{"name", "description"}})
+	if err == nil || !strings.Contains(err.Error(), `unknown column "description"`) {
+		t.Errorf("WriteMarkdown error = %v, want an unknown column error", err)
+	}
+}
+
+func TestWriteMarkdownHeaders(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "PORT", Type: "int", Required: true, Comment: "Port to listen on"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	opts := &MarkdownOptions{NoHeadings: true, Columns: []string{"name", "comment"}, Headers: []string{"Variable", "Description"}}
+	if err := WriteMarkdown(&buf, configs, opts); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	expected := `| Variable | Description       |
+|:---------|:------------------|
+| PORT     | Port to listen on |
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+
+	opts.Headers = []string{"Variable"}
+	if err := opts.Validate(); err == nil || !strings.Contains(err.Error(), "got 1 headers for 2 columns") {
+		t.Errorf("Validate() error = %v, want a header count error", err)
+	}
+}
+
+func TestWriteMarkdownNoComments(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "PORT", Type: "int", Required: true, Comment: "Port to listen on"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{NoHeadings: true, NoComments: true}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	expected := `| Name | Type | Required | Default |
+|:-----|:-----|:---------|:--------|
+| PORT | int  | true     |         |
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := WriteMarkdownList(&buf, configs, &MarkdownOptions{NoHeadings: true, NoComments: true}); err != nil {
+		t.Fatalf("WriteMarkdownList failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "Comment") {
+		t.Errorf("WriteMarkdownList() rendered a comment despite NoComments:\n%s", buf.String())
+	}
+}
+
+func TestWriteMarkdownCompact(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{
+				{Name: "PORT", Type: "int", Required: true, Comment: "Port to listen on"},
+				{Name: "HOST", Type: "string", Default: "localhost"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{NoHeadings: true, Compact: true}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	expected := `| Name | Type | Required | Default | Comment |
+| --- | --- | --- | --- | --- |
+| PORT | int | true |  | Port to listen on |
+| HOST | string | false | "localhost" |  |
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownHeadingLevel(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "PORT", Type: "int", Required: true}},
+		},
+	}
+
+	var buf bytes.Buffer
+	opts := &MarkdownOptions{HeadingLevel: 3, Partition: PartitionRequired, Columns: []string{"name"}}
+	if err := WriteMarkdown(&buf, configs, opts); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	expected := "### AppConfig\n\n#### Required\n\n"
+	if !strings.HasPrefix(buf.String(), expected) {
+		t.Errorf("WriteMarkdown() does not start with %q:\n%s", expected, buf.String())
+	}
+
+	for _, level := range []int{-1, 7} {
+		opts.HeadingLevel = level
+		if err := opts.Validate(); err == nil {
+			t.Errorf("Validate() accepted heading level %d", level)
+		}
+	}
+}
+
+func TestWriteMarkdownTOC(t *testing.T) {
+	configs := map[string]*Config{
+		"App": {
+			Keys:       []*Key{{Name: "NAME", Type: "string"}},
+			Breadcrumb: []string{"App"},
+		},
+		"DBConfig": {
+			Keys:       []*Key{{Name: "DB_HOST", Type: "string"}},
+			Breadcrumb: []string{"App", "Database"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownList(&buf, configs, &MarkdownOptions{TOC: true}); err != nil {
+		t.Fatalf("WriteMarkdownList failed: %v", err)
+	}
+	expected := "- [App](#app)\n- [App > Database](#app--database)\n\n## App\n\n"
+	if !strings.HasPrefix(buf.String(), expected) {
+		t.Errorf("WriteMarkdownList() does not start with %q:\n%s", expected, buf.String())
+	}
+}
+
+func TestGithubAnchor(t *testing.T) {
+	tests := map[string]string{
+		"Config":                 "config",
+		"App > Database > Pool":  "app--database--pool",
+		"example.com/app.Config": "examplecomappconfig",
+		"Web_Config (v2)":        "web_config-v2",
+		"Überkonfiguration-Ä":    "überkonfiguration-ä",
+	}
+	for title, expected := range tests {
+		if got := githubAnchor(title); got != expected {
+			t.Errorf("githubAnchor(%q) = %q, want %q", title, got, expected)
+		}
+	}
+}
+
+func TestFormatType(t *testing.T) {
+	if got := formatType(&Key{Type: "Port", Underlying: "int"}); got != "Port (int)" {
+		t.Errorf("formatType() = %q, want %q", got, "Port (int)")
+	}
+	if got := formatType(&Key{Type: "string"}); got != "string" {
+		t.Errorf("formatType() = %q, want %q", got, "string")
+	}
+}
+
+func TestFormatDefault(t *testing.T) {
+	tests := []struct {
+		key      *Key
+		expected string
+	}{
+		{key: &Key{Type: "string", Default: "localhost"}, expected: `"localhost"`},
+		{key: &Key{Type: "int", Default: "10"}, expected: "10"},
+		{key: &Key{Type: "float64", Default: "0.5"}, expected: "0.5"},
+		{key: &Key{Type: "bool", Default: "true"}, expected: "true"},
+		{key: &Key{Type: "Port", Underlying: "int", Default: "8080"}, expected: "8080"},
+		{key: &Key{Type: "time.Duration", Default: "5s"}, expected: `"5s"`},
+		{key: &Key{Type: "[]string", Default: "a,b,c"}, expected: "[a, b, c]"},
+		{key: &Key{Type: "[]int", Default: "1"}, expected: "[1]"},
+		{key: &Key{Type: "map[string]int", Default: "a:1,b:2"}, expected: "{a: 1, b: 2}"},
+		{key: &Key{Type: "*int", Default: "3"}, expected: "3"},
+		{key: &Key{Type: "int"}, expected: ""},
+	}
+	for _, tt := range tests {
+		if got := formatDefault(tt.key); got != tt.expected {
+			t.Errorf("formatDefault(%+v) = %q, want %q", tt.key, got, tt.expected)
+		}
+	}
+}
+
+func TestWriteMarkdownRawDefaults(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "HOST", Type: "string", Default: "localhost"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownList(&buf, configs, &MarkdownOptions{RawDefaults: true, NoHeadings: true}); err != nil {
+		t.Fatalf("WriteMarkdownList failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "- Default: localhost\n") {
+		t.Errorf("WriteMarkdownList() did not render the default bare:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{RawDefaults: true, Columns: []string{"default"}}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	expected := `## AppConfig
+
+| Default   |
+|:----------|
+| localhost |
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownShowEmptyDefault(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{
+				{Name: "HOST", Type: "string", Default: "localhost"},
+				{Name: "SUFFIX", Type: "string", EmptyDefault: true},
+				{Name: "TOKEN", Type: "string"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{ShowEmptyDefault: true, Columns: []string{"name", "default"}}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	expected := `## AppConfig
+
+| Name   | Default     |
+|:-------|:------------|
+| HOST   | "localhost" |
+| SUFFIX | ""          |
+| TOKEN  | (none)      |
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := WriteMarkdownList(&buf, configs, &MarkdownOptions{ShowEmptyDefault: true, NoHeadings: true}); err != nil {
+		t.Fatalf("WriteMarkdownList failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "**TOKEN**\n\n- Type: string\n- Required: false\n- Default: (none)\n") {
+		t.Errorf("WriteMarkdownList() did not render the missing default:\n%s", buf.String())
+	}
+}
+
+func TestWriteMarkdownSecret(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{
+				{Name: "PASSWORD", Type: "string", Default: "hunter2", Comment: "Database password", Secret: true},
+				{Name: "TOKEN", Type: "string", Secret: true},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{Columns: []string{"name", "default", "comment"}}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	expected := `## AppConfig
+
+| Name     | Default    | Comment              |
+|:---------|:-----------|:---------------------|
+| PASSWORD | (redacted) | 🔒 Database password |
+| TOKEN    |            | 🔒                   |
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+
+	buf.Reset()
+	if err := WriteMarkdownList(&buf, configs, &MarkdownOptions{RawDefaults: true, NoHeadings: true}); err != nil {
+		t.Fatalf("WriteMarkdownList failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("WriteMarkdownList() leaked a secret default:\n%s", buf.String())
+	}
+}
+
+func TestFormatRequired(t *testing.T) {
+	tests := []struct {
+		key      *Key
+		expected string
+	}{
+		{key: &Key{Required: true}, expected: "true"},
+		{key: &Key{Required: true, Default: "8080"}, expected: "true (overridden by default)"},
+		{key: &Key{Default: "8080"}, expected: "false"},
+	}
+	for _, tt := range tests {
+		if got := formatRequired(tt.key); got != tt.expected {
+			t.Errorf("formatRequired(%+v) = %q, want %q", tt.key, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatComment(t *testing.T) {
+	tests := []struct {
+		key      *Key
+		expected string
+	}{
+		{key: &Key{Comment: "Log level"}, expected: "Log level"},
+		{key: &Key{Comment: "Log level", Values: []string{"debug", "info"}}, expected: "Log level\nAllowed values: `debug`, `info`"},
+		{key: &Key{Values: []string{"debug"}}, expected: "Allowed values: `debug`"},
+	}
+	for _, tt := range tests {
+		if got := formatComment(tt.key); got != tt.expected {
+			t.Errorf("formatComment(%+v) = %q, want %q", tt.key, got, tt.expected)
+		}
+	}
+}
+
+func TestWriteMarkdownMultilineComment(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "PORT", Type: "int", Comment: "Port to listen on.\nZero picks a free port."}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{NoHeadings: true}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := `| Name | Type | Required | Default | Comment                                       |
+|:-----|:-----|:---------|:--------|:----------------------------------------------|
+| PORT | int  | false    |         | Port to listen on.<br>Zero picks a free port. |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownEscapesPipes(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "FORMAT", Type: "string", Default: "a|b", Comment: "use format a|b|c"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{NoHeadings: true}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := `| Name   | Type   | Required | Default | Comment            |
+|:-------|:-------|:---------|:--------|:-------------------|
+| FORMAT | string | false    | "a\|b"  | use format a\|b\|c |
+
+`
+	if diff := cmp.Diff(buf.String(), expected); diff != "" {
+		t.Errorf("WriteMarkdown output did not match expected:\n%s", diff)
+	}
+}
+
+func TestWriteMarkdownTypeCommentMarkdown(t *testing.T) {
+	configs := map[string]*Config{
+		"TestConfig": {
+			Keys: []*Key{
+				{Name: "Key1", Type: "string", Comment: "This is key 1"},
+			},
+			Comments: []*ast.CommentGroup{
+				{List: []*ast.Comment{
+					{Text: "// TestConfig configures the server."},
+					{Text: "//"},
+					{Text: "// Supported modes:"},
+					{Text: "//   - fast"},
+					{Text: "//   - safe"},
+					{Text: "//"},
+					{Text: "// ```sh"},
+					{Text: "// export KEY1=x"},
+					{Text: "// ```"},
+					{Text: "//"},
+					{Text: "// | Mode | Speed |"},
+					{Text: "// |------|-------|"},
+					{Text: "// | fast | high  |"},
+				}},
+				{List: []*ast.Comment{
+					{Text: "/*\n * Block comments\n * lose their asterisks.\n */"},
+				}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, configs, &MarkdownOptions{Columns: []string{"name"}}); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+
+	expected := "## TestConfig\n\n" +
+		"TestConfig configures the server.\n\n" +
+		"Supported modes:\n" +
+		"  - fast\n" +
+		"  - safe\n\n" +
+		"```sh\n" +
+		"export KEY1=x\n" +
+		"```\n\n" +
+		"| Mode | Speed |\n" +
+		"|------|-------|\n" +
+		"| fast | high  |\n\n" +
+		"Block comments\n" +
+		"lose their asterisks.\n\n" +
+		"| Name |\n" +
+		"|:-----|\n" +
+		"| Key1 |\n\n"
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+}
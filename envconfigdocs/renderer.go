@@ -0,0 +1,130 @@
+package envconfigdocs
+
+import (
+	"io"
+	"text/template"
+)
+
+// Renderer renders collected configs in an output format. The renderers of
+// this package wrap the Write functions; library users can plug in their own.
+type Renderer interface {
+	Render(w io.Writer, configs map[string]*Config) error
+}
+
+// RendererFunc adapts a function to the Renderer interface.
+type RendererFunc func(w io.Writer, configs map[string]*Config) error
+
+// Render calls f(w, configs).
+func (f RendererFunc) Render(w io.Writer, configs map[string]*Config) error {
+	return f(w, configs)
+}
+
+// MarkdownRenderer renders configs with WriteMarkdown.
+type MarkdownRenderer struct {
+	Options MarkdownOptions
+}
+
+// Render calls WriteMarkdown with r.Options.
+func (r MarkdownRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteMarkdown(w, configs, &r.Options)
+}
+
+// MarkdownListRenderer renders configs with WriteMarkdownList.
+type MarkdownListRenderer struct {
+	Options MarkdownOptions
+}
+
+// Render calls WriteMarkdownList with r.Options.
+func (r MarkdownListRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteMarkdownList(w, configs, &r.Options)
+}
+
+// MustSetRenderer renders configs with WriteMustSet.
+type MustSetRenderer struct {
+	Options MarkdownOptions
+}
+
+// Render calls WriteMustSet with r.Options.
+func (r MustSetRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteMustSet(w, configs, &r.Options)
+}
+
+// HTMLRenderer renders configs with WriteHTML.
+type HTMLRenderer struct {
+	Options MarkdownOptions
+}
+
+// Render calls WriteHTML with r.Options.
+func (r HTMLRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteHTML(w, configs, &r.Options)
+}
+
+// AsciiDocRenderer renders configs with WriteAsciiDoc.
+type AsciiDocRenderer struct {
+	Options MarkdownOptions
+}
+
+// Render calls WriteAsciiDoc with r.Options.
+func (r AsciiDocRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteAsciiDoc(w, configs, &r.Options)
+}
+
+// RSTRenderer renders configs with WriteRST.
+type RSTRenderer struct {
+	Options MarkdownOptions
+}
+
+// Render calls WriteRST with r.Options.
+func (r RSTRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteRST(w, configs, &r.Options)
+}
+
+// JSONRenderer renders configs with WriteJSON.
+type JSONRenderer struct{}
+
+// Render calls WriteJSON.
+func (JSONRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteJSON(w, configs)
+}
+
+// YAMLRenderer renders configs with WriteYAML.
+type YAMLRenderer struct{}
+
+// Render calls WriteYAML.
+func (YAMLRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteYAML(w, configs)
+}
+
+// JSONSchemaRenderer renders configs with WriteJSONSchema.
+type JSONSchemaRenderer struct{}
+
+// Render calls WriteJSONSchema.
+func (JSONSchemaRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteJSONSchema(w, configs)
+}
+
+// DotenvRenderer renders configs with WriteDotenv.
+type DotenvRenderer struct{}
+
+// Render calls WriteDotenv.
+func (DotenvRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteDotenv(w, configs)
+}
+
+// ShellValidateRenderer renders configs with WriteShellValidate.
+type ShellValidateRenderer struct{}
+
+// Render calls WriteShellValidate.
+func (ShellValidateRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteShellValidate(w, configs)
+}
+
+// TemplateRenderer renders configs with WriteTemplate.
+type TemplateRenderer struct {
+	Template *template.Template
+}
+
+// Render calls WriteTemplate with r.Template.
+func (r TemplateRenderer) Render(w io.Writer, configs map[string]*Config) error {
+	return WriteTemplate(w, configs, r.Template)
+}
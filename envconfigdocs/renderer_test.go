@@ -0,0 +1,47 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkdownRenderer(t *testing.T) {
+	configs := map[string]*Config{
+		"AppConfig": {
+			Keys: []*Key{{Name: "PORT", Type: "int", Required: true}},
+		},
+	}
+	opts := MarkdownOptions{NoHeadings: true, Columns: []string{"name", "type"}}
+
+	var expected, got bytes.Buffer
+	if err := WriteMarkdown(&expected, configs, &opts); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	var renderer Renderer = MarkdownRenderer{Options: opts}
+	if err := renderer.Render(&got, configs); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if diff := cmp.Diff(expected.String(), got.String()); diff != "" {
+		t.Errorf("Render() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRendererFunc(t *testing.T) {
+	var renderer Renderer = RendererFunc(func(w io.Writer, configs map[string]*Config) error {
+		for name := range configs {
+			io.WriteString(w, name)
+		}
+		return nil
+	})
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, map[string]*Config{"AppConfig": {}}); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if buf.String() != "AppConfig" {
+		t.Errorf("Render() wrote %q, want %q", buf.String(), "AppConfig")
+	}
+}
@@ -0,0 +1,113 @@
+package envconfigdocs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// WriteRST writes each config type as a reStructuredText section, its
+// comments and a grid table with the same columns as WriteMarkdown, for
+// Sphinx and other reStructuredText toolchains.
+func WriteRST(w io.Writer, configs map[string]*Config, opts *MarkdownOptions) error {
+	cols, err := opts.tableColumns()
+	if err != nil {
+		return err
+	}
+	writeLegend(w, opts)
+	withPackage := multiplePackages(configs)
+	for _, entry := range sortedConfigs(configs) {
+		config := entry.Value
+		if !opts.NoHeadings {
+			writeRSTTitle(w, rstText(sectionTitle(entry.Key, config)), '=')
+			if withPackage && config.Package != "" {
+				fmt.Fprintf(w, "Package: ``%s``\n\n", config.Package)
+			}
+			for _, c := range config.Comments {
+				fmt.Fprintf(w, "%s\n\n", rstText(strings.TrimSpace(c.Text())))
+			}
+			if config.Prefix != "" {
+				fmt.Fprintf(w, "Environment variables are prefixed with ``%s``.\n\n", config.namePrefix())
+			}
+		}
+		for _, group := range partitionKeys(config.Keys, opts) {
+			if group.Title != "" {
+				writeRSTTitle(w, group.Title, '-')
+			}
+			header := make([]string, len(cols))
+			for i, col := range cols {
+				header[i] = col.Header
+			}
+			rows := make([][]string, len(group.Keys))
+			for i, key := range group.Keys {
+				rows[i] = make([]string, len(cols))
+				for j, col := range cols {
+					rows[i][j] = rstText(col.Value(key))
+				}
+			}
+			writeRSTGridTable(w, header, rows)
+		}
+	}
+	return nil
+}
+
+// writeRSTTitle writes title underlined with the adornment character c.
+func writeRSTTitle(w io.Writer, title string, c byte) {
+	fmt.Fprintf(w, "%s\n%s\n\n", title, strings.Repeat(string(c), max(runewidth.StringWidth(title), 1)))
+}
+
+// writeRSTGridTable writes a grid table with a header row, sizing each
+// column to the display width of its widest line. Cells containing line
+// breaks span several lines of their row.
+func writeRSTGridTable(w io.Writer, header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			for _, line := range strings.Split(cell, "\n") {
+				widths[i] = max(widths[i], runewidth.StringWidth(line))
+			}
+		}
+	}
+	border := func(c string) {
+		for _, width := range widths {
+			fmt.Fprintf(w, "+%s", strings.Repeat(c, width+2))
+		}
+		fmt.Fprintln(w, "+")
+	}
+	writeRow := func(row []string) {
+		lines := make([][]string, len(row))
+		height := 1
+		for i, cell := range row {
+			lines[i] = strings.Split(cell, "\n")
+			height = max(height, len(lines[i]))
+		}
+		for n := range height {
+			for i, width := range widths {
+				var line string
+				if n < len(lines[i]) {
+					line = lines[i][n]
+				}
+				fmt.Fprintf(w, "| %s ", runewidth.FillRight(line, width))
+			}
+			fmt.Fprintln(w, "|")
+		}
+	}
+
+	border("-")
+	writeRow(header)
+	border("=")
+	for _, row := range rows {
+		writeRow(row)
+		border("-")
+	}
+	fmt.Fprintln(w)
+}
+
+// rstText escapes the characters reStructuredText reads as inline markup,
+// such as the trailing underscore of a reference or the asterisks of
+// emphasis, with backslashes.
+func rstText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "|", `\|`).Replace(s)
+}
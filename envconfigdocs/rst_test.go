@@ -0,0 +1,62 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"go/ast"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteRST(t *testing.T) {
+	configs := map[string]*Config{
+		"Config": {
+			Comments: []*ast.CommentGroup{{List: []*ast.Comment{{Text: "// Config is the app config."}}}},
+			Keys: []*Key{
+				{Name: "PORT", Type: "int", Required: true, Comment: "Port to listen on"},
+				{Name: "LOG_LEVEL", Type: "string", Default: "info", Comment: "Verbosity.\nOne of *debug* or info."},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRST(&buf, configs, &MarkdownOptions{}); err != nil {
+		t.Fatalf("WriteRST failed: %v", err)
+	}
+
+	expected := `Config
+======
+
+Config is the app config.
+
++------------+--------+----------+---------+---------------------------+
+| Name       | Type   | Required | Default | Comment                   |
++============+========+==========+=========+===========================+
+| PORT       | int    | true     |         | Port to listen on         |
++------------+--------+----------+---------+---------------------------+
+| LOG\_LEVEL | string | false    | "info"  | Verbosity.                |
+|            |        |          |         | One of \*debug\* or info. |
++------------+--------+----------+---------+---------------------------+
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteRST() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteRSTGridTableWideCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	writeRSTGridTable(&buf, []string{"Name", "Comment"}, [][]string{{"GREETING", "こんにちは"}})
+
+	// East Asian characters take two columns each
+	expected := `+----------+------------+
+| Name     | Comment    |
++==========+============+
+| GREETING | こんにちは |
++----------+------------+
+
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("writeRSTGridTable() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,41 @@
+package envconfigdocs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteShellValidate writes a POSIX shell snippet that fails with a message
+// naming the first required variable that is unset or empty, e.g.
+// : "${DATABASE_URL:?DATABASE_URL is required}". Comments are written on the
+// line above each check. Required keys with a default are skipped, since
+// envconfig does not enforce them.
+func WriteShellValidate(w io.Writer, configs map[string]*Config) error {
+	first := true
+	for _, entry := range sortedConfigs(configs) {
+		var required []*Key
+		for _, key := range entry.Value.Keys {
+			if key.Required && key.Default == "" {
+				required = append(required, key)
+			}
+		}
+		if len(required) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(w)
+		}
+		first = false
+		fmt.Fprintf(w, "# ---- %s ----\n", sectionTitle(entry.Key, entry.Value))
+		for _, key := range required {
+			if key.Comment != "" {
+				for _, line := range strings.Split(key.Comment, "\n") {
+					fmt.Fprintf(w, "# %s\n", line)
+				}
+			}
+			fmt.Fprintf(w, ": \"${%s:?%s is required}\"\n", key.Name, key.Name)
+		}
+	}
+	return nil
+}
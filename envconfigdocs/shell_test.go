@@ -0,0 +1,49 @@
+package envconfigdocs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteShellValidate(t *testing.T) {
+	configs := map[string]*Config{
+		"DBConfig": {
+			Keys: []*Key{
+				{Name: "DATABASE_URL", Type: "string", Required: true, Comment: "Database URL for connection\nsuch as postgres://localhost"},
+				{Name: "POOL_SIZE", Type: "int", Required: true, Default: "10"},
+				{Name: "API_KEY", Type: "string", Required: true},
+			},
+		},
+		"AppConfig": {
+			Keys: []*Key{
+				{Name: "GREETING", Type: "string", Default: "hello world"},
+			},
+		},
+		"LogConfig": {
+			Keys: []*Key{
+				{Name: "LOG_LEVEL", Type: "string", Required: true, Comment: "Minimum level to log"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteShellValidate(&buf, configs); err != nil {
+		t.Fatalf("WriteShellValidate failed: %v", err)
+	}
+
+	expected := `# ---- DBConfig ----
+# Database URL for connection
+# such as postgres://localhost
+: "${DATABASE_URL:?DATABASE_URL is required}"
+: "${API_KEY:?API_KEY is required}"
+
+# ---- LogConfig ----
+# Minimum level to log
+: "${LOG_LEVEL:?LOG_LEVEL is required}"
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("WriteShellValidate() mismatch (-want +got):\n%s", diff)
+	}
+}
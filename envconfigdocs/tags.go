@@ -0,0 +1,180 @@
+package envconfigdocs
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// fieldTag is the environment variable metadata declared by a field's tag.
+type fieldTag struct {
+	Name     string
+	Required bool
+	Default  string
+	// EmptyDefault is set when the tag gives an empty default explicitly.
+	EmptyDefault bool
+}
+
+// tagStyle describes the struct tag conventions of an envconfig-style
+// library.
+type tagStyle struct {
+	// NameKey is the tag key naming the environment variable of a field.
+	NameKey string
+	// Keys are the tag keys understood by the library.
+	Keys []string
+	// DescKey is the tag key holding the description of a field, if the
+	// library has one.
+	DescKey string
+	// Parse extracts the metadata of the field called name from its tag,
+	// reporting whether the field is read from the environment at all.
+	Parse func(name string, tag reflect.StructTag) (fieldTag, bool)
+	// StructPrefix returns the prefix the library prepends to the keys of
+	// a struct field or embedded struct with the tag, if any. It is nil for
+	// libraries that do not prefix structs this way.
+	StructPrefix func(tag reflect.StructTag) (string, bool)
+	// JoinPrefix returns the variable the library reads for name under the
+	// prefix passed to it by the caller, as ApplyPrefixes applies it.
+	JoinPrefix func(prefix, name string) string
+}
+
+// DefaultTagStyle is the style used when none is selected.
+const DefaultTagStyle = "kelsey"
+
+// tagStyles maps the names accepted in CollectOptions.TagStyle to their
+// conventions.
+var tagStyles = map[string]*tagStyle{
+	// github.com/kelseyhightower/envconfig
+	"kelsey": {
+		NameKey:    "envconfig",
+		Keys:       []string{"envconfig", "required", "default", "desc", "split_words", "ignored"},
+		DescKey:    "desc",
+		Parse:      parseKelseyTag,
+		JoinPrefix: envName,
+	},
+	// github.com/caarlos0/env
+	"caarlos0": {
+		NameKey:      "env",
+		Keys:         []string{"env", "envDefault", "envPrefix", "envSeparator", "envKeyValSeparator", "envExpand"},
+		Parse:        parseCaarlos0Tag,
+		StructPrefix: caarlos0Prefix,
+		JoinPrefix:   joinVerbatim,
+	},
+}
+
+// TagStyleOf returns the name of the tag style whose variable names are
+// read from the tag key, e.g. caarlos0 for env.
+func TagStyleOf(key string) (string, error) {
+	var keys []string
+	for _, name := range slices.Sorted(maps.Keys(tagStyles)) {
+		if tagStyles[name].NameKey == key {
+			return name, nil
+		}
+		keys = append(keys, tagStyles[name].NameKey)
+	}
+	return "", fmt.Errorf("unsupported tag %q, expected one of %s", key, strings.Join(keys, ", "))
+}
+
+// parseKelseyTag reads `envconfig:"NAME"`, `required` and `default`; fields
+// tagged `envconfig:"-"` or `ignored:"true"` are skipped. Options may also
+// follow the name after commas, as in `envconfig:"PORT,required"`. Without
+// an explicit name the variable is named after the field, split into words
+// with underscores when `split_words:"true"` is set. Either way the name is
+// upper-cased, since that is the variable envconfig reads first.
+func parseKelseyTag(field string, tag reflect.StructTag) (fieldTag, bool) {
+	value, ok := tag.Lookup("envconfig")
+	name, options, _ := strings.Cut(value, ",")
+	splitWords := tag.Get("split_words") == "true"
+	if (!ok && !splitWords) || name == "-" || tag.Get("ignored") == "true" {
+		return fieldTag{}, false
+	}
+	def, hasDefault := tag.Lookup("default")
+	if name == "" {
+		name = field
+		if splitWords {
+			name = splitFieldName(field)
+		}
+	}
+	name = envName("", name)
+	return fieldTag{
+		Name:         name,
+		Required:     tag.Get("required") == "true" || slices.Contains(strings.Split(options, ","), "required"),
+		Default:      def,
+		EmptyDefault: hasDefault && def == "",
+	}, true
+}
+
+// envName returns the variable envconfig reads for name under prefix,
+// PREFIX_NAME in upper case, or NAME alone without a prefix. Both the names
+// of kelsey tags and the prefixes ApplyPrefixes joins to them go through it,
+// so that a key reads the same in every section and format.
+func envName(prefix, name string) string {
+	if prefix == "" {
+		return strings.ToUpper(name)
+	}
+	return strings.ToUpper(prefix + "_" + name)
+}
+
+// parseCaarlos0Tag reads `env:"NAME,options..."` and `envDefault:"..."`,
+// where the required option marks the variable as required. Fields tagged
+// `env:"-"` are skipped.
+func parseCaarlos0Tag(_ string, tag reflect.StructTag) (fieldTag, bool) {
+	value, ok := tag.Lookup("env")
+	if !ok {
+		return fieldTag{}, false
+	}
+	name, options, _ := strings.Cut(value, ",")
+	if name == "" || name == "-" {
+		return fieldTag{}, false
+	}
+	def, hasDefault := tag.Lookup("envDefault")
+	return fieldTag{
+		Name:         name,
+		Required:     slices.Contains(strings.Split(options, ","), "required"),
+		Default:      def,
+		EmptyDefault: hasDefault && def == "",
+	}, true
+}
+
+// joinVerbatim prepends prefix to name as given, the way env.Options.Prefix
+// and envPrefix are applied by caarlos0/env.
+func joinVerbatim(prefix, name string) string {
+	return prefix + name
+}
+
+// caarlos0Prefix reads the prefix of a struct field from `envPrefix:"DB_"`
+// or the prefix option of `env:",prefix=DB_"`.
+func caarlos0Prefix(tag reflect.StructTag) (string, bool) {
+	if prefix, ok := tag.Lookup("envPrefix"); ok {
+		return prefix, true
+	}
+	_, options, _ := strings.Cut(tag.Get("env"), ",")
+	for _, option := range strings.Split(options, ",") {
+		if prefix, ok := strings.CutPrefix(option, "prefix="); ok {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+var (
+	gatherRegexp  = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+// splitFieldName joins the words of the CamelCase field name with
+// underscores, e.g. MaxConnections becomes Max_Connections and APIKey
+// becomes API_Key, using the same rules as envconfig's split_words.
+func splitFieldName(field string) string {
+	var words []string
+	for _, word := range gatherRegexp.FindAllString(field, -1) {
+		if m := acronymRegexp.FindStringSubmatch(word); len(m) == 3 {
+			words = append(words, m[1], m[2])
+		} else {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, "_")
+}
@@ -0,0 +1,168 @@
+package envconfigdocs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestCollectConfigTypesTagStyles(t *testing.T) {
+	tests := []struct {
+		style    string
+		source   string
+		expected []*Key
+	}{
+		{
+			style: "kelsey",
+			source: `
+package test
+
+type MyConfig struct {
+	Host  string ` + "`envconfig:\"HOST\" required:\"true\"`" + `
+	Port  int    ` + "`envconfig:\"PORT\" default:\"8080\"`" + `
+	Debug bool   ` + "`env:\"DEBUG\" envDefault:\"true\"`" + `
+	Cache string ` + "`envconfig:\"-\" default:\"memory\"`" + `
+	Temp  string ` + "`envconfig:\"TEMP\" ignored:\"true\"`" + `
+	Dir   string ` + "`split_words:\"true\" ignored:\"true\"`" + `
+	Shown string ` + "`envconfig:\"SHOWN\" ignored:\"false\"`" + `
+	Empty string ` + "`envconfig:\"EMPTY\" default:\"\"`" + `
+	Token string ` + "`envconfig:\"TOKEN,required\"`" + `
+	Level string ` + "`envconfig:\",required\"`" + `
+}
+`,
+			expected: []*Key{
+				{Name: "HOST", Type: "string", Required: true},
+				{Name: "PORT", Type: "int", Default: "8080"},
+				{Name: "SHOWN", Type: "string"},
+				{Name: "EMPTY", Type: "string", EmptyDefault: true},
+				{Name: "TOKEN", Type: "string", Required: true},
+				{Name: "LEVEL", Type: "string", Required: true},
+			},
+		},
+		{
+			style: "caarlos0",
+			source: `
+package test
+
+type MyConfig struct {
+	Host  string ` + "`env:\"HOST,required\"`" + `
+	Port  int    ` + "`env:\"PORT\" envDefault:\"8080\"`" + `
+	Debug bool   ` + "`envconfig:\"DEBUG\" default:\"true\"`" + `
+	Token string ` + "`env:\"TOKEN,notEmpty,required\"`" + `
+	Cache string ` + "`env:\"-\"`" + `
+	Empty string ` + "`env:\"EMPTY\" envDefault:\"\"`" + `
+}
+`,
+			expected: []*Key{
+				{Name: "HOST", Type: "string", Required: true},
+				{Name: "PORT", Type: "int", Default: "8080"},
+				{Name: "TOKEN", Type: "string", Required: true},
+				{Name: "EMPTY", Type: "string", EmptyDefault: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			pkg := parseTestPackage(t, tt.source)
+
+			result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{TagStyle: tt.style})
+
+			if diff := cmp.Diff(tt.expected, result["MyConfig"].Keys, ignorePos); diff != "" {
+				t.Errorf("MyConfig keys mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCollectConfigTypesSplitWords(t *testing.T) {
+	source := `
+package test
+
+type MyConfig struct {
+	MaxConnections int ` + "`split_words:\"true\" default:\"10\"`" + `
+	APIKey string ` + "`split_words:\"true\" required:\"true\"`" + `
+	Port int ` + "`envconfig:\"\"`" + `
+	DatabaseURL string ` + "`envconfig:\"DB_URL\" split_words:\"true\"`" + `
+	LogLevel string ` + "`envconfig:\"\" split_words:\"true\"`" + `
+	Internal string
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{})
+
+	expected := []*Key{
+		{Name: "MAX_CONNECTIONS", Type: "int", Default: "10"},
+		{Name: "API_KEY", Type: "string", Required: true},
+		{Name: "PORT", Type: "int"},
+		{Name: "DB_URL", Type: "string"},
+		{Name: "LOG_LEVEL", Type: "string"},
+	}
+	if diff := cmp.Diff(expected, result["MyConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("MyConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSplitFieldName(t *testing.T) {
+	tests := map[string]string{
+		"MaxConnections": "Max_Connections",
+		"APIKey":         "API_Key",
+		"DatabaseURL":    "Database_URL",
+		"Port":           "Port",
+		"HTTPServer2":    "HTTP_Server2",
+	}
+	for field, expected := range tests {
+		if got := splitFieldName(field); got != expected {
+			t.Errorf("splitFieldName(%q) = %q, want %q", field, got, expected)
+		}
+	}
+}
+
+func TestCollectConfigTypesCaarlos0Prefix(t *testing.T) {
+	source := `
+package test
+
+type DBConfig struct {
+	Host string ` + "`env:\"HOST\"`" + `
+}
+
+type ClusterConfig struct {
+	Primary DBConfig ` + "`envPrefix:\"PRIMARY_\"`" + `
+}
+
+type AppConfig struct {
+	ClusterConfig ` + "`envPrefix:\"APP_\"`" + `
+	Replica DBConfig ` + "`env:\",prefix=REPLICA_\"`" + `
+	DBConfig
+}
+`
+	pkg := parseTestPackage(t, source)
+
+	result := CollectConfigTypes([]*packages.Package{pkg}, &CollectOptions{TagStyle: "caarlos0"})
+
+	expected := []*Key{
+		{Name: "APP_PRIMARY_HOST", Type: "string"},
+		{Name: "REPLICA_HOST", Type: "string"},
+		{Name: "HOST", Type: "string"},
+	}
+	if diff := cmp.Diff(expected, result["AppConfig"].Keys, ignorePos); diff != "" {
+		t.Errorf("AppConfig keys mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTagStyleOf(t *testing.T) {
+	for key, expected := range map[string]string{"envconfig": "kelsey", "env": "caarlos0"} {
+		got, err := TagStyleOf(key)
+		if err != nil {
+			t.Fatalf("TagStyleOf(%q) failed: %v", key, err)
+		}
+		if got != expected {
+			t.Errorf("TagStyleOf(%q) = %q, want %q", key, got, expected)
+		}
+	}
+	if _, err := TagStyleOf("yaml"); err == nil || err.Error() != `unsupported tag "yaml", expected one of env, envconfig` {
+		t.Errorf("TagStyleOf(%q) error = %v, want an unsupported tag error", "yaml", err)
+	}
+}
@@ -0,0 +1,47 @@
+package envconfigdocs
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// TemplateConfig is the view of a config type passed to the template of
+// WriteTemplate.
+type TemplateConfig struct {
+	// Name is the name of the type, qualified with its package path when
+	// it clashes with a type in another package.
+	Name string
+	// Title is the breadcrumb of the type when a root type is given, or
+	// else its name.
+	Title    string
+	Package  string
+	Prefix   string
+	Comments []string
+	Keys     []*Key
+}
+
+// WriteTemplate executes tmpl with the configs, sorted by title, as a list
+// of TemplateConfig. The defaults of secrets are left out.
+func WriteTemplate(w io.Writer, configs map[string]*Config, tmpl *template.Template) error {
+	var data []*TemplateConfig
+	for _, entry := range sortedConfigs(configs) {
+		var comments []string
+		for _, c := range entry.Value.Comments {
+			comments = append(comments, strings.TrimSpace(c.Text()))
+		}
+		var keys []*Key
+		for _, key := range entry.Value.Keys {
+			keys = append(keys, publicKey(key))
+		}
+		data = append(data, &TemplateConfig{
+			Name:     entry.Key,
+			Title:    sectionTitle(entry.Key, entry.Value),
+			Package:  entry.Value.Package,
+			Prefix:   entry.Value.Prefix,
+			Comments: comments,
+			Keys:     keys,
+		})
+	}
+	return tmpl.Execute(w, data)
+}
@@ -0,0 +1,6 @@
+package broken
+
+// Config refers to a type that does not exist.
+type Config struct {
+	Addr Address `envconfig:"ADDR"`
+}
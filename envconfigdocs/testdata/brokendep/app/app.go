@@ -0,0 +1,8 @@
+package app
+
+import "github.com/wreulicke/envconfig-docs/envconfigdocs/testdata/brokendep/dep"
+
+// Config embeds a config of a dependency that fails to type-check.
+type Config struct {
+	dep.Base
+}
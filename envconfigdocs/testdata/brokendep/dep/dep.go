@@ -0,0 +1,10 @@
+package dep
+
+// Base is sound, but the package has a type error elsewhere.
+type Base struct {
+	Host string `envconfig:"HOST"`
+}
+
+func broken() int {
+	return "not an int"
+}
@@ -0,0 +1,12 @@
+package app
+
+import (
+	base "github.com/wreulicke/envconfig-docs/envconfigdocs/testdata/crosspkg/shared"
+)
+
+// AppConfig embeds a config declared in another package.
+type AppConfig struct {
+	base.BaseConfig
+	// Port to listen on
+	Port int `envconfig:"PORT" required:"true"`
+}
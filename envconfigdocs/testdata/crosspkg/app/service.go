@@ -0,0 +1,11 @@
+package app
+
+import (
+	base "github.com/wreulicke/envconfig-docs/envconfigdocs/testdata/crosspkg/shared"
+)
+
+// ServiceConfig nests configs declared in another package under prefixes.
+type ServiceConfig struct {
+	Logging  base.LoggingConfig `envconfig:"SERVICE"`
+	Fallback *base.BaseConfig   `envconfig:"FALLBACK"`
+}
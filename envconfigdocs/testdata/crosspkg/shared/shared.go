@@ -0,0 +1,14 @@
+package shared
+
+// BaseConfig is embedded by the configs of every service.
+type BaseConfig struct {
+	// Log level of the service
+	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
+	LoggingConfig
+}
+
+// LoggingConfig is embedded by BaseConfig.
+type LoggingConfig struct {
+	// Log output format
+	LogFormat string `envconfig:"LOG_FORMAT" default:"json"`
+}
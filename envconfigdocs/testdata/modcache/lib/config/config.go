@@ -0,0 +1,9 @@
+package config
+
+// LibConfig is the configuration of the library.
+type LibConfig struct {
+	// Endpoint of the remote service
+	Endpoint string `envconfig:"LIB_ENDPOINT" required:"true"`
+	// Retries before giving up
+	Retries int `envconfig:"LIB_RETRIES" default:"3"`
+}
@@ -0,0 +1,48 @@
+package envconfigdocs
+
+import (
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteYAML writes configs as a YAML mapping keyed by type name. Type names
+// are sorted; keys keep their declaration order.
+func WriteYAML(w io.Writer, configs map[string]*Config) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(configs); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// MarshalYAML encodes the config type with its doc comments flattened to
+// text, like MarshalJSON.
+func (c *Config) MarshalYAML() (any, error) {
+	var comments []string
+	for _, group := range c.Comments {
+		comments = append(comments, strings.TrimSpace(group.Text()))
+	}
+	return struct {
+		Comments   []string `yaml:"comments,omitempty"`
+		Package    string   `yaml:"package,omitempty"`
+		Prefix     string   `yaml:"prefix,omitempty"`
+		Breadcrumb []string `yaml:"breadcrumb,omitempty"`
+		Keys       []*Key   `yaml:"keys"`
+	}{
+		Comments:   comments,
+		Package:    c.Package,
+		Prefix:     c.Prefix,
+		Breadcrumb: c.Breadcrumb,
+		Keys:       c.Keys,
+	}, nil
+}
+
+// MarshalYAML encodes the key, leaving out the default of a secret, like
+// MarshalJSON.
+func (k *Key) MarshalYAML() (any, error) {
+	type plain Key
+	return (*plain)(publicKey(k)), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/wreulicke/envconfig-docs/envconfigdocs"
+	"golang.org/x/tools/go/packages"
+)
+
+// exampleSource is the sample config printed by the example command.
+const exampleSource = `package config
+
+// Config is the configuration of the service.
+type Config struct {
+	// Address to listen on
+	Addr string ` + "`envconfig:\"ADDR\" default:\":8080\"`" + `
+	// URL of the primary database
+	DatabaseURL string ` + "`envconfig:\"DATABASE_URL\" required:\"true\"`" + `
+	// Maximum number of open database connections
+	MaxConns int ` + "`envconfig:\"MAX_CONNS\" default:\"10\"`" + `
+	// Enable verbose logging
+	Debug bool ` + "`envconfig:\"DEBUG\"`" + `
+}
+`
+
+func newExampleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "example",
+		Short: "Print a sample config struct and the documentation generated from it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return writeExample(cmd.OutOrStdout())
+		},
+	}
+}
+
+// writeExample writes exampleSource followed by its Markdown documentation.
+func writeExample(w io.Writer) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "config.go", exampleSource, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse example: %w", err)
+	}
+	pkg := &packages.Package{
+		Fset:   fset,
+		Syntax: []*ast.File{file},
+	}
+	configs := envconfigdocs.CollectConfigTypes([]*packages.Package{pkg}, &envconfigdocs.CollectOptions{})
+
+	fmt.Fprintf(w, "```go\n%s```\n\n", exampleSource)
+	return envconfigdocs.WriteMarkdown(w, configs, &envconfigdocs.MarkdownOptions{})
+}
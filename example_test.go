@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteExample(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeExample(&buf); err != nil {
+		t.Fatalf("writeExample failed: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "```go\n"+exampleSource+"```\n") {
+		t.Errorf("output does not start with the example source:\n%s", got)
+	}
+	for _, row := range []string{
+		"| ADDR         | string | false    | \":8080\" | Address to listen on                        |",
+		"| DATABASE_URL | string | true     |         | URL of the primary database                 |",
+	} {
+		if !strings.Contains(got, row) {
+			t.Errorf("output does not contain %q:\n%s", row, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/wreulicke/envconfig-docs/envconfigdocs"
+)
+
+// execFormatPrefix starts --format values that delegate rendering to an
+// external command, e.g. "exec:python3 render.py".
+const execFormatPrefix = "exec:"
+
+// lookupFormat returns the output format called name: a built-in format
+// or an external command given as "exec:<command>".
+func lookupFormat(name string) (*outputFormat, error) {
+	if command, ok := strings.CutPrefix(name, execFormatPrefix); ok {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			return nil, errors.New("missing command in --format exec:")
+		}
+		return &outputFormat{
+			New: func(envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+				return envconfigdocs.RendererFunc(func(w io.Writer, configs map[string]*envconfigdocs.Config) error {
+					return runFormatter(w, args, configs)
+				})
+			},
+		}, nil
+	}
+	format, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %q", name)
+	}
+	return format, nil
+}
+
+// runFormatter runs the command args with configs serialized as JSON on
+// its stdin, copying its stdout to w.
+func runFormatter(w io.Writer, args []string, configs map[string]*envconfigdocs.Config) error {
+	input, err := json.Marshal(configs)
+	if err != nil {
+		return fmt.Errorf("failed to encode configs: %w", err)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("formatter %q failed: %w: %s", strings.Join(args, " "), err, msg)
+		}
+		return fmt.Errorf("formatter %q failed: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wreulicke/envconfig-docs/envconfigdocs"
+)
+
+func TestExecFormat(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat is not available")
+	}
+	configs := map[string]*envconfigdocs.Config{
+		"Config": {
+			Comments: []*ast.CommentGroup{{List: []*ast.Comment{{Text: "// Config is the app config."}}}},
+			Prefix:   "app",
+			Keys: []*envconfigdocs.Key{
+				{Name: "APP_PORT", Type: "int", Required: true, Comment: "Port to listen on"},
+				{Name: "APP_HOST", Type: "string", Default: "localhost"},
+			},
+		},
+	}
+
+	format, err := lookupFormat("exec:cat")
+	if err != nil {
+		t.Fatalf("lookupFormat failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := format.New(envconfigdocs.MarkdownOptions{}).Render(&buf, configs); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("formatter input is not JSON: %v\n%s", err, buf.String())
+	}
+	expected := map[string]any{
+		"Config": map[string]any{
+			"comments": []any{"Config is the app config."},
+			"prefix":   "app",
+			"keys": []any{
+				map[string]any{"name": "APP_PORT", "type": "int", "required": true, "comment": "Port to listen on"},
+				map[string]any{"name": "APP_HOST", "type": "string", "required": false, "default": "localhost"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("formatter input mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExecFormatFailure(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat is not available")
+	}
+	format, err := lookupFormat("exec:cat testdata/does-not-exist")
+	if err != nil {
+		t.Fatalf("lookupFormat failed: %v", err)
+	}
+	err = format.New(envconfigdocs.MarkdownOptions{}).Render(&bytes.Buffer{}, map[string]*envconfigdocs.Config{})
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Render error = %v, want an *exec.ExitError", err)
+	}
+	if !strings.Contains(err.Error(), "does-not-exist") {
+		t.Errorf("Render error %q does not include the formatter's stderr", err)
+	}
+
+	if _, err := lookupFormat("exec:"); err == nil {
+		t.Error("lookupFormat succeeded without a command")
+	}
+}
@@ -2,13 +2,16 @@ module github.com/wreulicke/envconfig-docs
 
 go 1.23.3
 
-require github.com/spf13/cobra v1.9.1
+require (
+	github.com/fatih/color v1.15.0
+	github.com/google/go-cmp v0.7.0
+	github.com/mattn/go-isatty v0.0.19
+	github.com/spf13/cobra v1.9.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/fatih/color v1.15.0 // indirect
-	github.com/google/go-cmp v0.7.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.19 // indirect
 	github.com/olekukonko/errors v0.0.0-20250405072817-4e6d85265da6 // indirect
 	github.com/olekukonko/ll v0.0.8 // indirect
 	golang.org/x/sys v0.31.0 // indirect
@@ -17,10 +20,10 @@ require (
 require (
 	github.com/gostaticanalysis/comment v1.5.0
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/mattn/go-runewidth v0.0.16
 	github.com/olekukonko/tablewriter v1.0.8
 	github.com/rivo/uniseg v0.2.0 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
+	github.com/spf13/pflag v1.0.6
 	golang.org/x/mod v0.24.0 // indirect
 	golang.org/x/sync v0.12.0 // indirect
 	golang.org/x/tools v0.31.0
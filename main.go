@@ -1,222 +1,543 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
-	"go/ast"
 	"io"
-	"iter"
-	"log"
 	"maps"
-	"reflect"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 
-	"github.com/gostaticanalysis/comment"
-	"github.com/olekukonko/tablewriter"
-	"github.com/olekukonko/tablewriter/renderer"
-	"github.com/olekukonko/tablewriter/tw"
 	"github.com/spf13/cobra"
-	"golang.org/x/tools/go/packages"
+	"github.com/spf13/pflag"
+	"github.com/wreulicke/envconfig-docs/envconfigdocs"
 )
 
-type configType struct {
-	Keys     []*configKey
-	Comments []*ast.CommentGroup
+// formats maps the names accepted by --format to their renderers.
+var formats = map[string]*outputFormat{
+	"markdown": {
+		New: func(opts envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.MarkdownRenderer{Options: opts}
+		},
+		Extension: ".md",
+		Comment:   "<!-- %s -->",
+	},
+	"markdown-list": {
+		New: func(opts envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.MarkdownListRenderer{Options: opts}
+		},
+		Extension: ".md",
+		Comment:   "<!-- %s -->",
+	},
+	"json": {
+		New: func(envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.JSONRenderer{}
+		},
+		Extension: ".json",
+	},
+	"yaml": {
+		New: func(envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.YAMLRenderer{}
+		},
+		Extension: ".yaml",
+		Comment:   "# %s",
+	},
+	"jsonschema": {
+		New: func(envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.JSONSchemaRenderer{}
+		},
+		Extension: ".json",
+	},
+	"dotenv": {
+		New: func(envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.DotenvRenderer{}
+		},
+		Extension: ".env",
+		Comment:   "# %s",
+	},
+	"html": {
+		New: func(opts envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.HTMLRenderer{Options: opts}
+		},
+		Extension: ".html",
+		Comment:   "<!-- %s -->",
+	},
+	"asciidoc": {
+		New: func(opts envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.AsciiDocRenderer{Options: opts}
+		},
+		Extension: ".adoc",
+		Comment:   "// %s",
+	},
+	"rst": {
+		New: func(opts envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.RSTRenderer{Options: opts}
+		},
+		Extension: ".rst",
+		Comment:   ".. %s",
+	},
+	"shell-validate": {
+		New: func(envconfigdocs.MarkdownOptions) envconfigdocs.Renderer {
+			return envconfigdocs.ShellValidateRenderer{}
+		},
+		Extension: ".sh",
+		Comment:   "# %s",
+	},
 }
 
-type configKey struct {
-	Name     string
-	Type     string
-	Required bool
-	Default  string
-	Comment  string
+// outputFormat is a format accepted by --format.
+type outputFormat struct {
+	// New returns the renderer of the format configured with opts.
+	New func(opts envconfigdocs.MarkdownOptions) envconfigdocs.Renderer
+	// Extension is appended to --output paths that have none.
+	Extension string
+	// Comment is the format string of a comment in the format, holding the
+	// --stamp text. Formats without comments, such as JSON, leave it empty
+	// and cannot be stamped.
+	Comment string
 }
 
-type decl struct {
-	Decl   *ast.GenDecl
-	Fields []*ast.Field
+// outputPath returns the file --output writes to: path itself when it has
+// an extension, or path with the extension of format appended.
+func outputPath(path string, format *outputFormat) string {
+	if filepath.Ext(path) != "" {
+		return path
+	}
+	return path + format.Extension
 }
 
-type entry[K comparable, V any] struct {
-	Key   K
-	Value V
+func main() {
+	// an interrupt ends --watch cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := newCommand().ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "envconfig-docs: %v\n", err)
+		os.Exit(1)
+	}
 }
 
-func entries[K comparable, V any](iter iter.Seq2[K, V]) func(yield func(*entry[K, V]) bool) {
-	return func(yield func(*entry[K, V]) bool) {
-		for k, v := range iter {
-			if !yield(&entry[K, V]{k, v}) {
-				break
-			}
-		}
+// headingLevel is the value of --heading-level. Unlike
+// MarkdownOptions.HeadingLevel, where zero selects the default, it must be
+// given from 1 to 6.
+type headingLevel int
+
+func (l *headingLevel) String() string { return strconv.Itoa(int(*l)) }
+
+func (l *headingLevel) Type() string { return "int" }
+
+func (l *headingLevel) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	if n < 1 || n > 6 {
+		return fmt.Errorf("heading level %d is out of range 1-6", n)
 	}
+	*l = headingLevel(n)
+	return nil
 }
 
-func collectDecls(files []*ast.File) map[string]*decl {
-	decls := make(map[string]*decl)
-	for _, file := range files {
-		for _, d := range file.Decls {
-			genDecl, ok := d.(*ast.GenDecl)
-			if !ok {
-				continue
-			}
-			for _, spec := range genDecl.Specs {
-				typeSpec, ok := spec.(*ast.TypeSpec)
-				if !ok {
-					continue
-				}
-				if _, ok := typeSpec.Type.(*ast.StructType); ok {
-					decls[typeSpec.Name.Name] = &decl{
-						Decl:   genDecl,
-						Fields: typeSpec.Type.(*ast.StructType).Fields.List,
-					}
-				}
-			}
-		}
-	}
-	return decls
+// options holds the flags controlling how documentation is generated. They
+// are shared by the root command and its subcommands.
+type options struct {
+	markdown         envconfigdocs.MarkdownOptions
+	collect          envconfigdocs.CollectOptions
+	format           string
+	legend           bool
+	splitRequired    bool
+	typePrefixes     map[string]string
+	tag              string
+	prefix           string
+	nameConvention   string
+	strict           bool
+	quiet            bool
+	stamp            bool
+	stats            bool
+	output           string
+	outputDir        string
+	checkOutput      bool
+	watch            bool
+	validateDefaults bool
+	requireDocs      bool
+	mustSet          bool
+	template         string
+	sort             string
+	types            []string
+	exclude          []string
+	packagesFrom     string
 }
 
-func collectConfigTypes(decls map[string]*decl, comments comment.Maps) map[string]*configType {
-	configs := make(map[string]*configType)
-	for name, decl := range decls {
-		for i, field := range decl.Fields {
-			if field.Tag == nil || field.Tag.Value == "" {
-				continue
-			}
-			// strip the backticks and parse the tag
-			tag := reflect.StructTag(field.Tag.Value[1 : len(field.Tag.Value)-1])
-			key, ok := tag.Lookup("envconfig")
-			if !ok {
-				continue
-			}
-			if _, ok := configs[name]; !ok {
-				configs[name] = &configType{
-					Keys: []*configKey{},
-				}
-				d, ok := decls[name]
-				if ok {
-					c := comments.CommentsByPos(d.Decl.TokPos)
-					configs[name].Comments = c
-				}
-			}
-			configKey := &configKey{
-				Name: key,
-				Type: field.Type.(*ast.Ident).Name,
-			}
-			configs[name].Keys = append(configs[name].Keys, configKey)
-			if required, ok := tag.Lookup("required"); ok {
-				configKey.Required = required == "true"
-			}
-			if def, ok := tag.Lookup("default"); ok {
-				configKey.Default = def
-			}
-			d, ok := decls[name]
-			if ok {
-				f := d.Fields[i]
-				configKey.Comment = strings.ReplaceAll(f.Doc.Text(), "\n", "")
-			}
-		}
+func (o *options) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.packagesFrom, "packages-from", "", "file listing package patterns to document, one per line; blank lines and # comments are ignored")
+	fs.StringVar(&o.format, "format", "markdown", "output format: markdown, markdown-list, json, yaml, jsonschema, dotenv, html, asciidoc, rst, shell-validate, or exec:<command> to pipe the configs as JSON through an external formatter")
+	fs.StringVar(&o.template, "template", "", "render with the Go text/template in this file instead of --format")
+	fs.BoolVar(&o.mustSet, "must-set", false, "list only the required variables without a default")
+	fs.BoolVar(&o.markdown.NoHeadings, "no-headings", false, "omit the per-type headings and type comments, emitting only the tables")
+	o.markdown.HeadingLevel = 2
+	fs.Var((*headingLevel)(&o.markdown.HeadingLevel), "heading-level", "number of # before each type's heading, from 1 to 6")
+	fs.BoolVar(&o.markdown.Compact, "compact", false, "render Markdown tables without padding the cells to a common width")
+	fs.BoolVar(&o.markdown.TOC, "toc", false, "write a table of contents linking to each type's section before the sections")
+	fs.BoolVar(&o.legend, "legend", false, "write a paragraph explaining the columns before the tables")
+	fs.StringVar(&o.markdown.Legend, "legend-text", "", "custom legend paragraph to write before the tables")
+	fs.StringVar(&o.markdown.Partition, "partition", "", "split each type's keys into sub-sections: required")
+	fs.BoolVar(&o.splitRequired, "split-required", false, "shorthand for --partition required")
+	fs.StringVar(&o.sort, "sort", "declaration", "order of keys within a type: declaration, name, or required (required keys first)")
+	fs.StringSliceVar(&o.markdown.Columns, "columns", nil, "comma-separated table columns to render, in order: name, flag, type, required, default, comment, source (default name,type,required,default,comment)")
+	fs.BoolVar(&o.markdown.RawDefaults, "raw-defaults", false, "render default values as written in the tag, without quoting string defaults")
+	fs.BoolVar(&o.markdown.ShowEmptyDefault, "show-empty-default", false, "render the default of keys without one as (none), and explicitly empty defaults as \"\"")
+	fs.BoolVar(&o.markdown.NoComments, "no-comments", false, "leave out the Comment column; applies after --columns")
+	fs.StringSliceVar(&o.markdown.Headers, "headers", nil, "comma-separated table headers, one per rendered column, e.g. Variable,Type,Mandatory,Default,Description")
+	fs.BoolVar(&o.markdown.WithFlags, "with-flags", false, "add a Flag column with the kebab-cased flag name derived from each environment variable")
+	fs.StringVar(&o.collect.TagStyle, "tag-style", envconfigdocs.DefaultTagStyle, "struct tag conventions: kelsey (kelseyhightower/envconfig) or caarlos0 (caarlos0/env)")
+	fs.StringVar(&o.tag, "tag", "", "struct tag key naming the variables: envconfig or env; selects the matching --tag-style")
+	fs.StringSliceVar(&o.types, "type", nil, "document only this config type (repeatable)")
+	fs.StringSliceVar(&o.exclude, "exclude", nil, "leave out config types whose names match this glob pattern, e.g. '*Test' (repeatable)")
+	fs.StringVar(&o.collect.Root, "root", "", "top-level config type; nested types get a breadcrumb heading such as App > Database > Pool")
+	fs.StringVar(&o.collect.DescTag, "desc-tag", "", "struct tag key to read descriptions from, e.g. help; fields without it fall back to their doc comment (default desc for the kelsey tag style)")
+	fs.StringSliceVar(&o.collect.DescTags, "desc-tags", nil, "comma-separated struct tag keys to read descriptions from, in order, e.g. desc,help; the first non-empty one wins, then the doc comment")
+	fs.BoolVar(&o.collect.DefaultConsts, "default-consts", false, "note the package constants whose value matches each default in its comment")
+	fs.StringVar(&o.collect.SecretTag, "secret-tag", envconfigdocs.DefaultSecretTag, "struct tag key marking fields that hold secrets with \"true\"; their defaults are redacted")
+	fs.StringVar(&o.collect.DescMapVar, "desc-map-var", "", "name of a package-level map[string]string of environment variable descriptions")
+	fs.StringVar(&o.prefix, "prefix", "", "envconfig prefix of every config type, as passed to envconfig.Process")
+	fs.StringToStringVar(&o.typePrefixes, "type-prefix", nil, "envconfig prefix of a config type as Type=PREFIX (repeatable)")
+	fs.BoolVar(&o.validateDefaults, "validate-defaults", false, "fail when a default cannot be parsed as the type of its field")
+	fs.BoolVar(&o.requireDocs, "require-docs", false, "fail when a required variable has no comment or description")
+	fs.StringVar(&o.nameConvention, "name-convention", "", "regular expression every environment variable name must match")
+	fs.BoolVar(&o.strict, "strict", false, "treat warnings as errors")
+	fs.BoolVarP(&o.quiet, "quiet", "q", false, "do not print warnings; errors are still reported")
+	fs.BoolVar(&o.stats, "stats", false, "report the number of documented config types and variables on stderr")
+	fs.BoolVar(&o.stamp, "stamp", false, "start the output with a comment recording the tool version and a hash of the input packages")
+}
+
+// generate writes the documentation of the packages matched by args and
+// --packages-from to w, reporting warnings to errOut unless --quiet is set.
+func (o *options) generate(w, errOut io.Writer, args []string) error {
+	doc, err := o.document(errOut, args)
+	if err != nil {
+		return err
 	}
-	return configs
+	return doc.write(w, doc.configs)
 }
 
-func loadPackages(packageName string) ([]*packages.Package, error) {
-	return packages.Load(&packages.Config{
-		Mode: packages.NeedName | packages.NeedSyntax | packages.NeedTypes,
-		Dir:  packageName,
-	})
+// document is the documentation collected from a set of packages, ready to
+// be rendered.
+type document struct {
+	renderer envconfigdocs.Renderer
+	configs  map[string]*envconfigdocs.Config
+	// stamp is written before the rendered configs when --stamp is set.
+	stamp string
 }
 
-func collectConfigTypesFromPackages(pkgs []*packages.Package) map[string]*configType {
-	configs := map[string]*configType{}
+// write renders configs, a subset of d.configs, to w.
+func (d *document) write(w io.Writer, configs map[string]*envconfigdocs.Config) error {
+	if d.stamp != "" {
+		fmt.Fprintf(w, "%s\n\n", d.stamp)
+	}
+	return d.renderer.Render(w, configs)
+}
 
+// document collects the config types of the packages matched by args and
+// --packages-from, reporting warnings to errOut unless --quiet is set.
+func (o *options) document(errOut io.Writer, args []string) (*document, error) {
+	format, err := lookupFormat(o.format)
+	if err != nil {
+		return nil, err
+	}
+	if o.tag != "" {
+		style, err := envconfigdocs.TagStyleOf(o.tag)
+		if err != nil {
+			return nil, err
+		}
+		o.collect.TagStyle = style
+	}
+	if err := o.collect.Validate(); err != nil {
+		return nil, err
+	}
+	if o.splitRequired {
+		o.markdown.Partition = envconfigdocs.PartitionRequired
+	}
+	if err := o.markdown.Validate(); err != nil {
+		return nil, err
+	}
+	compareKeys, ok := envconfigdocs.KeyOrders[cmp.Or(o.sort, "declaration")]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort order %q", o.sort)
+	}
+	if o.legend && o.markdown.Legend == "" {
+		o.markdown.Legend = envconfigdocs.DefaultLegend
+	}
+	renderer := format.New(o.markdown)
+	if o.mustSet {
+		renderer = envconfigdocs.MustSetRenderer{Options: o.markdown}
+	}
+	comment := format.Comment
+	if o.mustSet {
+		comment = formats["markdown"].Comment
+	}
+	if o.template != "" {
+		renderer, err = templateRenderer(o.template)
+		if err != nil {
+			return nil, err
+		}
+		comment = ""
+	}
+	patterns, err := o.patterns(args)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := envconfigdocs.LoadPackages(patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	var warnings []string
 	for _, pkg := range pkgs {
-		decls := collectDecls(pkg.Syntax)
-		comment := comment.New(pkg.Fset, pkg.Syntax)
-
-		configInPkg := collectConfigTypes(decls, comment)
-		maps.Copy(configs, configInPkg)
+		warnings = append(warnings, envconfigdocs.CheckTagTypos(pkg, &o.collect)...)
+	}
+	o.collect.Warnf = func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+	configs := envconfigdocs.CollectConfigTypes(pkgs, &o.collect)
+	if len(configs) == 0 {
+		// an empty doc usually means the wrong packages were given
+		warnings = append(warnings, fmt.Sprintf("no envconfig-tagged structs found in %s", strings.Join(patterns, ", ")))
+	}
+	if err := envconfigdocs.ApplyPrefixes(configs, o.prefixes(configs), &o.collect); err != nil {
+		return nil, fmt.Errorf("failed to apply --type-prefix: %w", err)
+	}
+	envconfigdocs.SortKeys(configs, compareKeys)
+	if o.collect.Root != "" && !envconfigdocs.HasBreadcrumbs(configs) {
+		return nil, fmt.Errorf("root type %q not found", o.collect.Root)
+	}
+	if err := envconfigdocs.SelectTypes(configs, o.types); err != nil {
+		return nil, err
+	}
+	if err := envconfigdocs.ExcludeTypes(configs, o.exclude); err != nil {
+		return nil, fmt.Errorf("invalid --exclude: %w", err)
+	}
+	if o.validateDefaults {
+		if problems := envconfigdocs.CheckDefaults(configs); len(problems) > 0 {
+			return nil, fmt.Errorf("invalid defaults:\n  %s", strings.Join(problems, "\n  "))
+		}
+	}
+	if o.requireDocs {
+		if problems := envconfigdocs.CheckDocs(configs); len(problems) > 0 {
+			return nil, fmt.Errorf("undocumented required variables:\n  %s", strings.Join(problems, "\n  "))
+		}
+	}
+	if o.nameConvention != "" {
+		re, err := regexp.Compile(o.nameConvention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --name-convention: %w", err)
+		}
+		warnings = append(warnings, envconfigdocs.CheckNameConvention(configs, re)...)
+	}
+	if o.stamp && comment == "" {
+		// a stamp would make JSON and the like unparsable
+		warnings = append(warnings, "--stamp is ignored since the output format has no comments")
 	}
 
-	return configs
+	if !o.quiet {
+		for _, warning := range warnings {
+			fmt.Fprintf(errOut, "warning: %s\n", warning)
+		}
+	}
+	if o.strict && len(warnings) > 0 {
+		return nil, fmt.Errorf("%d warning(s) reported in strict mode", len(warnings))
+	}
+	if o.stats {
+		fmt.Fprintln(errOut, summary(configs))
+	}
+	doc := &document{renderer: renderer, configs: configs}
+	if o.stamp && comment != "" {
+		text, err := stamp(pkgs)
+		if err != nil {
+			return nil, err
+		}
+		doc.stamp = fmt.Sprintf(comment, text)
+	}
+	return doc, nil
 }
 
-func writeMarkdown(w io.Writer, configs map[string]*configType) error {
-	sortedEntries := slices.SortedFunc(entries(maps.All(configs)), func(a, b *entry[string, *configType]) int {
-		return strings.Compare(a.Key, b.Key)
-	})
-
-	for _, entry := range sortedEntries {
-		name := entry.Key
-		config := entry.Value
-
-		// write markdown
-		fmt.Fprintf(w, "## %s\n\n", name)
-
-		if len(config.Comments) > 0 {
-			for _, c := range config.Comments {
-				for _, line := range strings.Split(c.Text(), "\n") {
-					fmt.Fprintf(w, "%s\n", line)
-				}
-			}
+// patterns returns the package patterns to document: args followed by the
+// patterns listed in --packages-from.
+func (o *options) patterns(args []string) ([]string, error) {
+	patterns := args
+	if o.packagesFrom != "" {
+		listed, err := readPackageList(o.packagesFrom)
+		if err != nil {
+			return nil, err
 		}
+		patterns = append(slices.Clip(patterns), listed...)
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New("no packages given")
+	}
+	return patterns, nil
+}
 
-		table := tablewriter.NewTable(w,
-			tablewriter.WithRenderer(renderer.NewMarkdown()),
-			tablewriter.WithConfig(tablewriter.NewConfigBuilder().
-				Header().Alignment().WithGlobal(tw.AlignLeft).Build().
-				Header().Formatting().WithAutoFormat(tw.Off).Build().Build().
-				Build()),
-		)
-
-		table.Header([]string{"Name", "Type", "Required", "Default", "Comment"})
+// summary returns a line counting the config types in configs, their
+// variables and how many of them are required.
+func summary(configs map[string]*envconfigdocs.Config) string {
+	var keys, required int
+	for _, config := range configs {
+		keys += len(config.Keys)
 		for _, key := range config.Keys {
-			defaults := ""
-			if key.Default != "" {
-				defaults = fmt.Sprintf("%q", key.Default)
-			}
-			err := table.Append(
-				key.Name,
-				key.Type,
-				fmt.Sprintf("%t", key.Required),
-				defaults,
-				key.Comment,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to append row: %w", err)
+			if key.Required {
+				required++
 			}
 		}
-		err := table.Render()
-		if err != nil {
-			return fmt.Errorf("failed to render table: %w", err)
+	}
+	return fmt.Sprintf("Documented %d config types, %d variables (%d required)", len(configs), keys, required)
+}
+
+// prefixes returns the prefix of each config type: the one given by
+// --type-prefix, or else the one given by --prefix.
+func (o *options) prefixes(configs map[string]*envconfigdocs.Config) map[string]string {
+	if o.prefix == "" {
+		return o.typePrefixes
+	}
+	prefixes := maps.Clone(o.typePrefixes)
+	if prefixes == nil {
+		prefixes = map[string]string{}
+	}
+	for name := range configs {
+		if _, ok := prefixes[name]; !ok {
+			prefixes[name] = o.prefix
+		}
+	}
+	return prefixes
+}
+
+// readPackageList reads newline-separated package patterns from the file
+// at path, ignoring blank lines and lines starting with #.
+func readPackageList(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package list: %w", err)
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
 
-		fmt.Fprintln(w)
+// run generates the documentation of the packages matched by args and
+// writes it to --output or --output-dir, or to stdout when neither is
+// given. With --check, the output file is compared with the generated
+// documentation instead of being written.
+func (o *options) run(stdout, errOut io.Writer, args []string) error {
+	if o.outputDir != "" {
+		if o.output != "" {
+			return errors.New("--output and --output-dir cannot be used together")
+		}
+		if o.checkOutput {
+			return errors.New("--check requires --output")
+		}
+		return o.writeOutputDir(errOut, args)
+	}
+	if o.output == "" {
+		if o.checkOutput {
+			return errors.New("--check requires --output")
+		}
+		return o.generate(stdout, errOut, args)
+	}
+	format, err := lookupFormat(o.format)
+	if err != nil {
+		return err
+	}
+	path := outputPath(o.output, format)
+	if o.checkOutput {
+		return o.check(errOut, path, args)
+	}
+	// render in memory first so that a failure leaves an existing file intact
+	var buf bytes.Buffer
+	if err := o.generate(&buf, errOut, args); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
 	}
 	return nil
 }
 
-func main() {
-	if err := newCommand().Execute(); err != nil {
-		log.Fatalf("failed to execute command: %v", err)
+// writeOutputDir writes the documentation of each package matched by args
+// to its own file under --output-dir, named after the package's import path
+// with the extension of the format, e.g. DIR/example.com/app/config.md.
+func (o *options) writeOutputDir(errOut io.Writer, args []string) error {
+	format, err := lookupFormat(o.format)
+	if err != nil {
+		return err
+	}
+	doc, err := o.document(errOut, args)
+	if err != nil {
+		return err
+	}
+	// render every file in memory first so that a failure writes none
+	files := map[string][]byte{}
+	for pkgPath, configs := range envconfigdocs.GroupByPackage(doc.configs) {
+		var buf bytes.Buffer
+		if err := doc.write(&buf, configs); err != nil {
+			return err
+		}
+		files[filepath.Join(o.outputDir, filepath.FromSlash(pkgPath)+format.Extension)] = buf.Bytes()
+	}
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if err := os.WriteFile(path, files[path], 0o644); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
 	}
+	return nil
 }
 
 func newCommand() *cobra.Command {
+	o := &options{}
 	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "Generate configuration documentation from Go source code",
 		Long:  `This command generates markdown documentation for configuration structures annotated with envconfig tags.`,
-		Args:  cobra.ExactArgs(1),
+		// cobra adds --version and prints "config version <version>"
+		Version: toolVersion(),
+		// main reports errors itself, without cobra's "Error:" prefix
+		SilenceErrors: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if o.packagesFrom != "" {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			pkgs, err := loadPackages(args[0])
-			if err != nil {
-				return fmt.Errorf("failed to load packages: %w", err)
+			// failures past argument parsing are not usage errors
+			cmd.SilenceUsage = true
+			if o.watch {
+				return o.runWatching(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr(), args)
 			}
-			configs := collectConfigTypesFromPackages(pkgs)
-			return writeMarkdown(cmd.OutOrStdout(), configs)
+			return o.run(cmd.OutOrStdout(), cmd.ErrOrStderr(), args)
 		},
 	}
+	o.addFlags(cmd.Flags())
+	registerCompletions(cmd)
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "write to this file instead of stdout; the format's extension is added when it has none")
+	cmd.Flags().StringVar(&o.outputDir, "output-dir", "", "write each package's documentation to DIR/<import path> plus the format's extension, creating directories as needed")
+	cmd.Flags().BoolVar(&o.watch, "watch", false, "keep running and regenerate the output whenever a .go file of the documented packages changes")
+	cmd.Flags().BoolVar(&o.checkOutput, "check", false, "do not write --output but fail with a diff when it is out of date, like the check command")
+	cmd.AddCommand(newCheckCommand())
+	cmd.AddCommand(newExampleCommand())
 	return cmd
 }
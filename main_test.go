@@ -2,224 +2,439 @@ package main
 
 import (
 	"bytes"
-	"go/ast"
-	"go/parser"
-	"go/token"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	"golang.org/x/tools/go/packages"
+	"github.com/spf13/cobra"
+	"github.com/wreulicke/envconfig-docs/envconfigdocs"
 )
 
-func TestWriteMarkdown(t *testing.T) {
-	configs := map[string]*configType{
-		"TestConfig": {
-			Keys: []*configKey{
-				{Name: "Key1", Type: "string", Required: true, Default: "default1", Comment: "This is key 1"},
-				{Name: "Key2", Type: "int", Required: false, Default: "0", Comment: "This is key 2"},
-			},
-			Comments: []*ast.CommentGroup{
-				{List: []*ast.Comment{{Text: "// This is a test config"}}},
-			},
-		},
+func TestOutputPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		format   string
+		expected string
+	}{
+		{path: "docs", format: "markdown", expected: "docs.md"},
+		{path: "docs/config", format: "markdown-list", expected: "docs/config.md"},
+		{path: "docs/config.markdown", format: "markdown", expected: "docs/config.markdown"},
+		{path: "README.txt", format: "markdown", expected: "README.txt"},
+	}
+	for _, tt := range tests {
+		if got := outputPath(tt.path, formats[tt.format]); got != tt.expected {
+			t.Errorf("outputPath(%q, %q) = %q, want %q", tt.path, tt.format, got, tt.expected)
+		}
 	}
+}
 
-	var buf bytes.Buffer
-	if err := writeMarkdown(&buf, configs); err != nil {
-		t.Fatalf("writeMarkdown failed: %v", err)
+func TestRunOutput(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config")
+	o := &options{format: "markdown", output: output}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err != nil {
+		t.Fatalf("run failed: %v\n%s", err, errOut.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("run wrote to stdout despite --output: %q", stdout.String())
 	}
 
-	expected := `## TestConfig
+	got, err := os.ReadFile(output + ".md")
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	expected, err := os.ReadFile("testdata/check/config.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(expected), string(got)); diff != "" {
+		t.Errorf("output file mismatch (-want +got):\n%s", diff)
+	}
+}
 
-This is a test config
+func TestRunCheck(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config.md")
+	o := &options{format: "markdown", output: output, checkOutput: true}
 
-| Name | Type   | Required | Default    | Comment       |
-|:-----|:-------|:---------|:-----------|:--------------|
-| Key1 | string | true     | "default1" | This is key 1 |
-| Key2 | int    | false    | "0"        | This is key 2 |
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("run error = %v, want one wrapping fs.ErrNotExist", err)
+	}
 
-`
-	if diff := cmp.Diff(buf.String(), expected); diff != "" {
-		t.Errorf("writeMarkdown output did not match expected:\n%s", diff)
+	expected, err := os.ReadFile("testdata/check/config.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(output, expected, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err != nil {
+		t.Errorf("run failed on an up-to-date file: %v\n%s", err, errOut.String())
+	}
+
+	if err := os.WriteFile(output, []byte("outdated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	errOut.Reset()
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err == nil {
+		t.Error("run succeeded on an outdated file")
+	}
+	if !strings.Contains(errOut.String(), "is out of date") {
+		t.Errorf("run did not report a diff: %q", errOut.String())
+	}
+	if got, _ := os.ReadFile(output); string(got) != "outdated\n" {
+		t.Errorf("run overwrote the output file with --check: %q", got)
 	}
 }
 
-func TestCollectConfigTypesFromPackages(t *testing.T) {
-	tests := []struct {
-		name     string
-		source   string
-		expected map[string]*configType
-	}{
-		{
-			name: "single config with envconfig tags",
-			source: `
-package test
-
-// MyConfig is a test configuration
-type MyConfig struct {
-	// Database URL for connection
-	DatabaseURL string ` + "`envconfig:\"DATABASE_URL\" required:\"true\" default:\"localhost:5432\"`" + `
-	// API Key for authentication
-	APIKey string ` + "`envconfig:\"API_KEY\" required:\"false\"`" + `
-	// Max connections allowed
-	MaxConnections int ` + "`envconfig:\"MAX_CONN\" default:\"10\"`" + `
-}
-`,
-			expected: map[string]*configType{
-				"MyConfig": {
-					Keys: []*configKey{
-						{
-							Name:     "DATABASE_URL",
-							Type:     "string",
-							Required: true,
-							Default:  "localhost:5432",
-							Comment:  "Database URL for connection",
-						},
-						{
-							Name:     "API_KEY",
-							Type:     "string",
-							Required: false,
-							Default:  "",
-							Comment:  "API Key for authentication",
-						},
-						{
-							Name:     "MAX_CONN",
-							Type:     "int",
-							Required: false,
-							Default:  "10",
-							Comment:  "Max connections allowed",
-						},
-					},
-				},
-			},
-		},
-		{
-			name: "multiple configs in same package",
-			source: `
-package test
-
-type Config1 struct {
-	Field1 string ` + "`envconfig:\"FIELD1\"`" + `
-}
-
-type Config2 struct {
-	Field2 int ` + "`envconfig:\"FIELD2\" required:\"true\"`" + `
-}
-`,
-			expected: map[string]*configType{
-				"Config1": {
-					Keys: []*configKey{
-						{Name: "FIELD1", Type: "string", Required: false},
-					},
-				},
-				"Config2": {
-					Keys: []*configKey{
-						{Name: "FIELD2", Type: "int", Required: true},
-					},
-				},
-			},
-		},
-		{
-			name: "struct without envconfig tags",
-			source: `
-package test
+func TestRunOutputError(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "missing", "config.md")
+	o := &options{format: "markdown", output: output}
 
-type NoEnvConfig struct {
-	Field1 string
-	Field2 int ` + "`json:\"field2\"`" + `
+	var stdout, errOut bytes.Buffer
+	err := o.run(&stdout, &errOut, []string{"testdata/check"})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("run error = %v, want one wrapping fs.ErrNotExist", err)
+	}
 }
-`,
-			expected: map[string]*configType{},
-		},
+
+func TestRunQuiet(t *testing.T) {
+	o := &options{format: "markdown", nameConvention: "^APP_", quiet: true}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if errOut.Len() != 0 {
+		t.Errorf("run wrote warnings despite --quiet: %q", errOut.String())
+	}
+
+	o.strict = true
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err == nil {
+		t.Error("run succeeded with warnings in strict mode")
+	}
+}
+
+func TestRunNoConfigs(t *testing.T) {
+	o := &options{format: "markdown"}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/empty"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if diff := cmp.Diff("warning: no envconfig-tagged structs found in testdata/empty\n", errOut.String()); diff != "" {
+		t.Errorf("stderr mismatch (-want +got):\n%s", diff)
+	}
+
+	o.strict = true
+	if err := o.run(&stdout, &errOut, []string{"testdata/empty"}); err == nil {
+		t.Error("run succeeded without configs in strict mode")
+	}
+}
+
+func TestRunRequireDocs(t *testing.T) {
+	o := &options{format: "markdown", requireDocs: true}
+
+	var stdout, errOut bytes.Buffer
+	err := o.run(&stdout, &errOut, []string{"testdata/undocumented"})
+	if err == nil || !strings.Contains(err.Error(), "Config.TOKEN: required but undocumented") {
+		t.Errorf("run error = %v, want undocumented required variables", err)
+	}
+
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err != nil {
+		t.Errorf("run failed without required variables: %v", err)
+	}
+}
+
+func TestRunStats(t *testing.T) {
+	o := &options{format: "markdown", stats: true}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/check", "./envconfigdocs/testdata/crosspkg/shared"}); err != nil {
+		t.Fatalf("run failed: %v\n%s", err, errOut.String())
+	}
+	if diff := cmp.Diff("Documented 3 config types, 4 variables (0 required)\n", errOut.String()); diff != "" {
+		t.Errorf("stats mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunSplitRequired(t *testing.T) {
+	o := &options{format: "markdown", splitRequired: true}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "### Optional\n") {
+		t.Errorf("output has no Optional sub-section:\n%s", stdout.String())
+	}
+	if strings.Contains(stdout.String(), "### Required\n") {
+		t.Errorf("output has an empty Required sub-section:\n%s", stdout.String())
 	}
+}
 
+func TestRunPrefixNested(t *testing.T) {
+	tests := []struct {
+		format string
+		root   string
+	}{
+		{format: "markdown"},
+		{format: "markdown", root: "AppConfig"},
+		{format: "json"},
+		{format: "dotenv"},
+		{format: "shell-validate"},
+	}
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Parse the source code
-			fset := token.NewFileSet()
-			file, err := parser.ParseFile(fset, "test.go", tt.source, parser.ParseComments)
-			if err != nil {
-				t.Fatalf("failed to parse source: %v", err)
-			}
+		o := &options{format: tt.format, prefix: "myapp"}
+		o.collect.Root = tt.root
 
-			// Create a mock package
-			pkg := &packages.Package{
-				Fset:   fset,
-				Syntax: []*ast.File{file},
-			}
+		var stdout, errOut bytes.Buffer
+		if err := o.run(&stdout, &errOut, []string{"testdata/nested"}); err != nil {
+			t.Fatalf("run --format %s failed: %v\n%s", tt.format, err, errOut.String())
+		}
+		// the DBConfig section names its keys as read through AppConfig
+		if strings.Contains(stdout.String(), "MYAPP_HOST") {
+			t.Errorf("--format %s --root %q output has MYAPP_HOST:\n%s", tt.format, tt.root, stdout.String())
+		}
+		if !strings.Contains(stdout.String(), "MYAPP_DB_HOST") {
+			t.Errorf("--format %s --root %q output has no MYAPP_DB_HOST:\n%s", tt.format, tt.root, stdout.String())
+		}
+	}
+}
 
-			// Test the function
-			result := collectConfigTypesFromPackages([]*packages.Package{pkg})
+func TestRunEffectiveNames(t *testing.T) {
+	for _, format := range slices.Sorted(maps.Keys(formats)) {
+		for _, root := range []string{"", "AppConfig"} {
+			o := &options{format: format, prefix: "myapp"}
+			o.collect.Root = root
 
-			// Compare results (ignoring Comments field for simplicity)
-			for _, config := range result {
-				config.Comments = nil
+			var stdout, errOut bytes.Buffer
+			if err := o.run(&stdout, &errOut, []string{"testdata/nested"}); err != nil {
+				t.Fatalf("run --format %s failed: %v\n%s", format, err, errOut.String())
 			}
-
-			if diff := cmp.Diff(tt.expected, result); diff != "" {
-				t.Errorf("collectConfigTypesFromPackages() mismatch (-want +got):\n%s", diff)
+			// reStructuredText escapes underscores
+			output := strings.ReplaceAll(stdout.String(), `\_`, "_")
+			// explicit and split_words names alike are prefixed and
+			// upper-cased in every section, nested ones included
+			for _, name := range []string{"MYAPP_DB_HOST", "MYAPP_DB_MAX_CONNS"} {
+				if n := strings.Count(output, name); n < 2 {
+					t.Errorf("--format %s --root %q output has %s %d times, want it in both sections:\n%s", format, root, name, n, output)
+				}
+			}
+			for _, name := range []string{"MYAPP_HOST", "MYAPP_MAX_CONNS", "host", "MaxConns"} {
+				if strings.Contains(output, name) {
+					t.Errorf("--format %s --root %q output has %s:\n%s", format, root, name, output)
+				}
 			}
-		})
+		}
+	}
+}
+
+func TestCommandRequiresPackages(t *testing.T) {
+	cmd := newCommand()
+	cmd.SetArgs(nil)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err == nil {
+		t.Error("command succeeded without packages")
 	}
 }
 
-func TestCollectConfigTypesFromPackagesMultiplePackages(t *testing.T) {
-	// Test with multiple packages
-	source1 := `
-package pkg1
+func TestOptionsPrefixes(t *testing.T) {
+	configs := map[string]*envconfigdocs.Config{"AppConfig": {}, "DBConfig": {}}
+
+	o := &options{typePrefixes: map[string]string{"DBConfig": "DB"}}
+	if diff := cmp.Diff(map[string]string{"DBConfig": "DB"}, o.prefixes(configs)); diff != "" {
+		t.Errorf("prefixes() without --prefix mismatch (-want +got):\n%s", diff)
+	}
 
-type Config1 struct {
-	Field1 string ` + "`envconfig:\"FIELD1\"`" + `
+	o.prefix = "MYAPP"
+	expected := map[string]string{"AppConfig": "MYAPP", "DBConfig": "DB"}
+	if diff := cmp.Diff(expected, o.prefixes(configs)); diff != "" {
+		t.Errorf("prefixes() with --prefix mismatch (-want +got):\n%s", diff)
+	}
 }
-`
-	source2 := `
-package pkg2
 
-type Config2 struct {
-	Field2 string ` + "`envconfig:\"FIELD2\"`" + `
+func TestReadPackageList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packages.txt")
+	content := "# services\n./cmd/api\n\n  ./cmd/worker  \n# ./cmd/legacy\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readPackageList(path)
+	if err != nil {
+		t.Fatalf("readPackageList failed: %v", err)
+	}
+	expected := []string{"./cmd/api", "./cmd/worker"}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("readPackageList mismatch (-want +got):\n%s", diff)
+	}
 }
-`
 
-	fset := token.NewFileSet()
-	file1, err := parser.ParseFile(fset, "pkg1.go", source1, parser.ParseComments)
+func TestRunPackagesFrom(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "packages.txt")
+	if err := os.WriteFile(list, []byte("# shared config\nenvconfigdocs/testdata/crosspkg/shared\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o := &options{format: "markdown", packagesFrom: list}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err != nil {
+		t.Fatalf("run failed: %v\n%s", err, errOut.String())
+	}
+	for _, heading := range []string{"## Config\n", "## BaseConfig\n", "## LoggingConfig\n"} {
+		if !strings.Contains(stdout.String(), heading) {
+			t.Errorf("output does not contain %q:\n%s", heading, stdout.String())
+		}
+	}
+}
+
+func TestRunOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	o := &options{format: "markdown", outputDir: dir}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/check", "./envconfigdocs/testdata/crosspkg/shared"}); err != nil {
+		t.Fatalf("run failed: %v\n%s", err, errOut.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("run wrote to stdout despite --output-dir: %q", stdout.String())
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "github.com/wreulicke/envconfig-docs/testdata/check.md"))
 	if err != nil {
-		t.Fatalf("failed to parse source1: %v", err)
+		t.Fatalf("failed to read output: %v", err)
 	}
-	file2, err := parser.ParseFile(fset, "pkg2.go", source2, parser.ParseComments)
+	expected, err := os.ReadFile("testdata/check/config.md")
 	if err != nil {
-		t.Fatalf("failed to parse source2: %v", err)
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(expected), string(got)); diff != "" {
+		t.Errorf("output file mismatch (-want +got):\n%s", diff)
+	}
+	shared, err := os.ReadFile(filepath.Join(dir, "github.com/wreulicke/envconfig-docs/envconfigdocs/testdata/crosspkg/shared.md"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if strings.Contains(string(shared), "## Config\n") {
+		t.Errorf("output of the shared package contains the check package's Config:\n%s", shared)
+	}
+}
+
+func TestRunOutputDirWithOutput(t *testing.T) {
+	o := &options{format: "markdown", output: "config.md", outputDir: t.TempDir()}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err == nil {
+		t.Error("run succeeded with both --output and --output-dir")
+	}
+}
+
+func TestRunNoPackages(t *testing.T) {
+	o := &options{format: "markdown"}
+
+	var stdout, errOut bytes.Buffer
+	if err := o.run(&stdout, &errOut, nil); err == nil {
+		t.Error("run succeeded without packages")
+	}
+}
+
+func TestCommandVersion(t *testing.T) {
+	defer func(v string) { version = v }(version)
+	version = "v1.2.3"
+
+	cmd := newCommand()
+	cmd.SetArgs([]string{"--version"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("config version v1.2.3\n", stdout.String()); diff != "" {
+		t.Errorf("version mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCommandHeadingLevel(t *testing.T) {
+	for _, level := range []string{"0", "7"} {
+		cmd := newCommand()
+		cmd.SetArgs([]string{"--heading-level", level, "testdata/check"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "out of range 1-6") {
+			t.Errorf("--heading-level %s error = %v, want the range error", level, err)
+		}
 	}
 
-	pkg1 := &packages.Package{
-		Fset:   fset,
-		Syntax: []*ast.File{file1},
+	cmd := newCommand()
+	cmd.SetArgs([]string{"--heading-level", "3", "testdata/check"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("--heading-level 3 failed: %v", err)
 	}
-	pkg2 := &packages.Package{
-		Fset:   fset,
-		Syntax: []*ast.File{file2},
+	if !strings.HasPrefix(stdout.String(), "### ") {
+		t.Errorf("--heading-level 3 output does not start with ###:\n%s", stdout.String())
 	}
+}
 
-	result := collectConfigTypesFromPackages([]*packages.Package{pkg1, pkg2})
+func TestCommandExcludeInvalid(t *testing.T) {
+	cmd := newCommand()
+	cmd.SetArgs([]string{"--exclude", "[App", "testdata/check"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	if err == nil || !strings.HasPrefix(err.Error(), `invalid --exclude: invalid pattern "[App"`) {
+		t.Errorf("--exclude [App error = %v, want the flag named", err)
+	}
+}
 
-	expected := map[string]*configType{
-		"Config1": {
-			Keys: []*configKey{
-				{Name: "FIELD1", Type: "string", Required: false},
-			},
+func TestCommandCompletion(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{cobra.ShellCompRequestCmd, "--format", "a"},
+			expected: "asciidoc\n:4\n",
 		},
-		"Config2": {
-			Keys: []*configKey{
-				{Name: "FIELD2", Type: "string", Required: false},
-			},
+		{
+			args:     []string{cobra.ShellCompRequestCmd, "check", "--sort", ""},
+			expected: "declaration\nname\nrequired\n:4\n",
 		},
 	}
-
-	// Ignore Comments field for comparison
-	for _, config := range result {
-		config.Comments = nil
+	for _, tt := range tests {
+		cmd := newCommand()
+		cmd.SetArgs(tt.args)
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(io.Discard)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v: unexpected error: %v", tt.args, err)
+		}
+		if diff := cmp.Diff(tt.expected, stdout.String()); diff != "" {
+			t.Errorf("%v: completions mismatch (-want +got):\n%s", tt.args, diff)
+		}
 	}
 
-	if diff := cmp.Diff(expected, result); diff != "" {
-		t.Errorf("collectConfigTypesFromPackages() with multiple packages mismatch (-want +got):\n%s", diff)
+	cmd := newCommand()
+	cmd.SetArgs([]string{"completion", "bash"})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("completion bash: unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "__start_config") {
+		t.Errorf("completion bash: expected a bash completion script, got:\n%s", stdout.String())
 	}
 }
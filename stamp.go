@@ -0,0 +1,77 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// stampPrefix starts the text of the stamp comment written by --stamp.
+const stampPrefix = "generated by envconfig-docs"
+
+// stamp returns the text recording the tool version and a hash of the
+// packages the documentation was generated from, to be wrapped in a comment
+// of the output format.
+func stamp(pkgs []*packages.Package) (string, error) {
+	hash, err := hashPackages(pkgs)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s; input sha256:%s", stampPrefix, toolVersion(), hash), nil
+}
+
+// version is the version of the binary, set at build time with
+// -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+// toolVersion returns the version of the running binary: the one set at
+// build time, or else the module version recorded by go install.
+func toolVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
+
+// hashPackages returns the hex-encoded SHA-256 of the import paths and Go
+// source files of pkgs. File names are hashed without their directory so
+// that the hash does not depend on where the sources are checked out.
+func hashPackages(pkgs []*packages.Package) (string, error) {
+	sorted := slices.SortedFunc(slices.Values(pkgs), func(a, b *packages.Package) int {
+		return cmp.Compare(a.PkgPath, b.PkgPath)
+	})
+	h := sha256.New()
+	for _, pkg := range sorted {
+		fmt.Fprintf(h, "package %s\n", pkg.PkgPath)
+		for _, file := range pkg.GoFiles {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return "", fmt.Errorf("failed to hash %s: %w", file, err)
+			}
+			fmt.Fprintf(h, "file %s %d\n", filepath.Base(file), len(content))
+			h.Write(content)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// stripStamp removes a leading stamp comment, in the comment syntax of any
+// format, and the blank line following it from s.
+func stripStamp(s string) string {
+	first, rest, _ := strings.Cut(s, "\n")
+	if !strings.Contains(first, stampPrefix) {
+		return s
+	}
+	return strings.TrimPrefix(rest, "\n")
+}
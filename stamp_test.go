@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"gopkg.in/yaml.v3"
+)
+
+func TestStamp(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.go")
+	if err := os.WriteFile(file, []byte("package config\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pkgs := []*packages.Package{{PkgPath: "example.com/config", GoFiles: []string{file}}}
+
+	first, err := stamp(pkgs)
+	if err != nil {
+		t.Fatalf("stamp failed: %v", err)
+	}
+	if !strings.HasPrefix(first, stampPrefix) {
+		t.Errorf("stamp() = %q, want a text starting with %q", first, stampPrefix)
+	}
+
+	if err := os.WriteFile(file, []byte("package config\n\n// changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second, err := stamp(pkgs)
+	if err != nil {
+		t.Fatalf("stamp failed: %v", err)
+	}
+	if first == second {
+		t.Errorf("stamp() did not change after the input changed: %q", first)
+	}
+}
+
+func TestCheckIgnoresStamp(t *testing.T) {
+	current, err := os.ReadFile("testdata/check/config.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamped := filepath.Join(t.TempDir(), "config.md")
+	content := "<!-- " + stampPrefix + " v0.0.0; input sha256:0000 -->\n\n" + string(current)
+	if err := os.WriteFile(stamped, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stamp := range []bool{false, true} {
+		o := &options{format: "markdown", stamp: stamp}
+		var errOut bytes.Buffer
+		if err := o.check(&errOut, stamped, []string{"testdata/check"}); err != nil {
+			t.Errorf("check with stamp=%t failed: %v\n%s", stamp, err, errOut.String())
+		}
+	}
+}
+
+func TestRunStampFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		// prefix starts the stamped output, or is empty when the format
+		// cannot hold a stamp
+		prefix string
+		parse  func([]byte) error
+	}{
+		{format: "markdown", prefix: "<!-- " + stampPrefix},
+		{format: "json", parse: func(b []byte) error { return json.Unmarshal(b, new(any)) }},
+		{format: "jsonschema", parse: func(b []byte) error { return json.Unmarshal(b, new(any)) }},
+		{format: "yaml", prefix: "# " + stampPrefix, parse: func(b []byte) error { return yaml.Unmarshal(b, new(any)) }},
+		{format: "dotenv", prefix: "# " + stampPrefix},
+		{format: "shell-validate", prefix: "# " + stampPrefix},
+	}
+	for _, tt := range tests {
+		o := &options{format: tt.format, stamp: true}
+
+		var stdout, errOut bytes.Buffer
+		if err := o.run(&stdout, &errOut, []string{"testdata/check"}); err != nil {
+			t.Fatalf("run --format %s failed: %v\n%s", tt.format, err, errOut.String())
+		}
+		if tt.prefix == "" {
+			if !strings.Contains(errOut.String(), "--stamp is ignored") {
+				t.Errorf("--format %s did not warn about the ignored stamp: %q", tt.format, errOut.String())
+			}
+		} else if !strings.HasPrefix(stdout.String(), tt.prefix) {
+			t.Errorf("--format %s output does not start with %q:\n%s", tt.format, tt.prefix, stdout.String())
+		}
+		if tt.parse != nil {
+			if err := tt.parse(stdout.Bytes()); err != nil {
+				t.Errorf("stamped --format %s output does not parse: %v\n%s", tt.format, err, stdout.String())
+			}
+		}
+	}
+}
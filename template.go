@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/wreulicke/envconfig-docs/envconfigdocs"
+)
+
+// templateRenderer returns a renderer executing the text/template in the
+// file at path with the configs, sorted by title, as data.
+func templateRenderer(path string) (envconfigdocs.Renderer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	renderer := envconfigdocs.TemplateRenderer{Template: tmpl}
+	return envconfigdocs.RendererFunc(func(w io.Writer, configs map[string]*envconfigdocs.Config) error {
+		if err := renderer.Render(w, configs); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		return nil
+	}), nil
+}
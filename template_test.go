@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wreulicke/envconfig-docs/envconfigdocs"
+)
+
+func TestTemplateRenderer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs.tmpl")
+	tmpl := `{{range .}}h2. {{.Title}}
+{{range .Comments}}{{.}}
+{{end}}{{range .Keys}}* {{.Name}} ({{.Type}}){{if .Required}} required{{end}}
+{{end}}{{end}}`
+	if err := os.WriteFile(path, []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configs := map[string]*envconfigdocs.Config{
+		"DBConfig": {
+			Keys: []*envconfigdocs.Key{{Name: "DB_HOST", Type: "string", Required: true}},
+		},
+		"AppConfig": {
+			Comments: []*ast.CommentGroup{{List: []*ast.Comment{{Text: "// AppConfig is the app config."}}}},
+			Keys:     []*envconfigdocs.Key{{Name: "PORT", Type: "int"}},
+		},
+	}
+
+	renderer, err := templateRenderer(path)
+	if err != nil {
+		t.Fatalf("templateRenderer failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, configs); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := `h2. AppConfig
+AppConfig is the app config.
+* PORT (int)
+h2. DBConfig
+* DB_HOST (string) required
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("template output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTemplateRendererSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs.tmpl")
+	if err := os.WriteFile(path, []byte(`{{range .}}{{range .Keys}}{{.Name}}={{.Default}}{{end}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configs := map[string]*envconfigdocs.Config{
+		"Config": {
+			Keys: []*envconfigdocs.Key{{Name: "TOKEN", Type: "string", Default: "s3cr3t", Secret: true}},
+		},
+	}
+
+	renderer, err := templateRenderer(path)
+	if err != nil {
+		t.Fatalf("templateRenderer failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, configs); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if diff := cmp.Diff("TOKEN=", buf.String()); diff != "" {
+		t.Errorf("template output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTemplateRendererParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.tmpl")
+	if err := os.WriteFile(path, []byte("{{range .}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := templateRenderer(path); err == nil {
+		t.Error("templateRenderer succeeded on a malformed template")
+	}
+}
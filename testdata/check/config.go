@@ -0,0 +1,7 @@
+package check
+
+// Config is checked against config.md.
+type Config struct {
+	// Address to listen on
+	Addr string `envconfig:"ADDR" default:":8080"`
+}
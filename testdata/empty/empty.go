@@ -0,0 +1,6 @@
+package empty
+
+// Options is not read from the environment.
+type Options struct {
+	Verbose bool
+}
@@ -0,0 +1,16 @@
+package nested
+
+// DBConfig is the database connection.
+type DBConfig struct {
+	// Host of the database server
+	Host string `envconfig:"host" required:"true"`
+	// Maximum number of open connections
+	MaxConns int `split_words:"true" required:"true"`
+}
+
+// AppConfig nests DBConfig under DB.
+type AppConfig struct {
+	DB DBConfig `envconfig:"db"`
+	// Port to listen on
+	Port int `envconfig:"PORT" default:"8080"`
+}
@@ -0,0 +1,8 @@
+package undocumented
+
+// Config has a required variable without a description.
+type Config struct {
+	Token string `envconfig:"TOKEN" required:"true"`
+	// Address to listen on
+	Addr string `envconfig:"ADDR" required:"true"`
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// watchInterval is how often --watch polls the package directories for
+// changes. A change is acted upon once the files stay unchanged for one
+// more interval, so that the burst of writes of an editor or a branch
+// switch triggers a single regeneration. Polling rather than fsnotify keeps
+// the tool free of the dependency and behaves the same on every platform;
+// each poll only stats the directories and their known .go files, reading
+// a directory again only when its modification time says entries changed.
+var watchInterval = 500 * time.Millisecond
+
+// runWatching runs the command, then runs it again whenever a .go file in
+// the directories of the documented packages changes, until ctx is done.
+// Failures of later runs are reported to errOut without ending the watch.
+func (o *options) runWatching(ctx context.Context, stdout, errOut io.Writer, args []string) error {
+	if o.checkOutput {
+		return errors.New("--watch cannot be used with --check")
+	}
+	for {
+		if err := o.run(stdout, errOut, args); err != nil {
+			fmt.Fprintf(errOut, "envconfig-docs: %v\n", err)
+		}
+		// the packages are listed again on each round to pick up new ones
+		dirs, err := o.packageDirs(args)
+		if err != nil {
+			return err
+		}
+		if err := waitForChange(ctx, dirs, watchInterval); err != nil {
+			if errors.Is(err, ctx.Err()) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// packageDirs returns the directories of the packages matched by args and
+// --packages-from.
+func (o *options) packageDirs(args []string) ([]string, error) {
+	patterns, err := o.patterns(args)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedFiles}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	dirs := map[string]bool{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.GoFiles {
+			dirs[filepath.Dir(file)] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(dirs)), nil
+}
+
+// waitForChange polls the .go files in dirs every interval and returns once
+// a file was added, removed or modified and then left unchanged for one
+// interval. It returns ctx.Err() when ctx is done first.
+func waitForChange(ctx context.Context, dirs []string, interval time.Duration) error {
+	files, err := scanGoFiles(dirs)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		changed, err := files.refresh()
+		if err != nil {
+			return err
+		}
+		if changed {
+			pending = true
+			continue
+		}
+		if pending {
+			return nil
+		}
+	}
+}
+
+// goFiles records the .go files directly in a set of directories, keyed by
+// directory.
+type goFiles map[string]*goDir
+
+// goDir is the state of a directory when last polled.
+type goDir struct {
+	// ModTime is the modification time of the directory itself, which
+	// changes when entries are added, removed or renamed. It is zero for
+	// a directory that does not exist.
+	ModTime time.Time
+	// Files maps the .go files in the directory to their modification
+	// times.
+	Files map[string]time.Time
+}
+
+// scanGoFiles reads the .go files directly in dirs. Directories that do not
+// exist are recorded as empty.
+func scanGoFiles(dirs []string) (goFiles, error) {
+	files := goFiles{}
+	for _, dir := range dirs {
+		files[dir] = &goDir{}
+		if _, err := files.refreshDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// refresh updates files and reports whether any .go file was added, removed
+// or modified since the last call.
+func (files goFiles) refresh() (bool, error) {
+	changed := false
+	for dir := range files {
+		c, err := files.refreshDir(dir)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || c
+	}
+	return changed, nil
+}
+
+// refreshDir updates the state of dir, listing its entries only when the
+// modification time of dir changed and otherwise statting the .go files
+// already known.
+func (files goFiles) refreshDir(dir string) (bool, error) {
+	state := files[dir]
+	var modTime time.Time
+	info, err := os.Stat(dir)
+	switch {
+	case err == nil:
+		modTime = info.ModTime()
+	case !errors.Is(err, os.ErrNotExist):
+		return false, err
+	}
+	if !modTime.Equal(state.ModTime) || state.Files == nil {
+		current, err := readGoFiles(dir)
+		if err != nil {
+			return false, err
+		}
+		changed := !maps.Equal(state.Files, current)
+		state.ModTime, state.Files = modTime, current
+		return changed, nil
+	}
+	changed := false
+	for name, last := range state.Files {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			delete(state.Files, name)
+			changed = true
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if !info.ModTime().Equal(last) {
+			state.Files[name] = info.ModTime()
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// readGoFiles returns the modification time of each .go file directly in
+// dir, or no files when dir does not exist.
+func readGoFiles(dir string) (map[string]time.Time, error) {
+	files := map[string]time.Time{}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = info.ModTime()
+	}
+	return files, nil
+}
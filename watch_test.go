@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWaitForChange(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.go")
+	if err := os.WriteFile(file, []byte("package config\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- waitForChange(context.Background(), []string{dir}, 10*time.Millisecond)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	// a later modification time than the first write, whatever the
+	// resolution of the file system
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("waitForChange failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitForChange did not return after a change")
+	}
+}
+
+func TestWaitForChangeNewFile(t *testing.T) {
+	dir := t.TempDir()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- waitForChange(context.Background(), []string{dir}, 10*time.Millisecond)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "config.go"), []byte("package config\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("waitForChange failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitForChange did not return after a file was added")
+	}
+}
+
+func TestWaitForChangeIgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- waitForChange(ctx, []string{dir}, 10*time.Millisecond)
+	}()
+	time.Sleep(30 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# config\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != context.DeadlineExceeded {
+		t.Errorf("waitForChange() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRunWatching(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config.md")
+	o := &options{format: "markdown", output: output}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- o.runWatching(ctx, os.Stdout, os.Stderr, []string{"testdata/check"})
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(output); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("runWatching did not write the output")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("runWatching failed: %v", err)
+	}
+}